@@ -0,0 +1,153 @@
+package say
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	aggMu       sync.Mutex
+	aggInterval time.Duration
+	aggStop     func() // Stops the periodic flush.
+	aggs        map[aggKey]*aggregate
+	aggKeys     []aggKey // In order of first appearance.
+	aggOn       int32    // Accessed atomically.
+)
+
+// An aggKey identifies the metrics aggregated together.
+type aggKey struct {
+	typ    Type
+	key    string
+	unit   string
+	tenant string
+	out    io.Writer
+}
+
+// An aggregate accumulates the metrics of a key during an interval.
+type aggregate struct {
+	msg           *Message // The first metric of the interval.
+	count         int
+	sum, min, max float64
+}
+
+// SetAggregation makes Say accumulate the EVENT and VALUE metrics in memory
+// and print one message per key, tenant and output every interval instead of
+// one message per call. Use it for hot loops sending thousands of metrics per
+// second:
+//
+//	EVENT cache.hit:5234	| count=5234
+//	VALUE query.duration:12.5ms	| count=812 min=2 max=153
+//
+// An EVENT is the sum of the increments and a VALUE is the mean of the values.
+// The count data pair is the number of metrics aggregated. The message of a
+// key has the data of its first metric in the interval; the data of the other
+// metrics is dropped.
+//
+// The messages are printed every interval of the clock set with SetClock, by
+// a goroutine. The pending messages are also printed by FlushAggregates, by
+// Flush and when Say exits the program. An interval of 0 disables the
+// aggregation, which is the default, and prints the pending messages.
+//
+// This function must not be called concurrently with itself.
+func SetAggregation(interval time.Duration) {
+	aggMu.Lock()
+	stop := aggStop
+	aggStop = nil
+	aggMu.Unlock()
+	if stop != nil {
+		stop()
+	}
+
+	aggMu.Lock()
+	due := takeAggregates()
+	aggInterval = interval
+	on := int32(0)
+	if interval > 0 {
+		on = 1
+		aggStop = every(interval, FlushAggregates)
+	}
+	atomic.StoreInt32(&aggOn, on)
+	aggMu.Unlock()
+
+	for _, m := range due {
+		deliver(m, false)
+	}
+}
+
+// FlushAggregates prints the metrics aggregated so far without waiting for
+// the end of the interval.
+func FlushAggregates() {
+	aggMu.Lock()
+	due := takeAggregates()
+	aggMu.Unlock()
+
+	for _, m := range due {
+		deliver(m, false)
+	}
+}
+
+// aggregateMessage adds msg to its aggregate. It reports whether msg has been
+// aggregated, in which case it must not be sent.
+func aggregateMessage(msg *Message) bool {
+	if atomic.LoadInt32(&aggOn) == 0 || !msg.isNumeric() {
+		return false
+	}
+	if msg.Type != TypeEvent && msg.Type != TypeValue {
+		return false
+	}
+
+	aggMu.Lock()
+	if aggInterval == 0 {
+		aggMu.Unlock()
+		return false
+	}
+
+	k := aggKey{msg.Type, msg.Key, msg.Unit, msg.Tenant, msg.out}
+	a := aggs[k]
+	if a == nil {
+		a = &aggregate{msg: getMessage(), min: msg.Value, max: msg.Value}
+		copyMessage(a.msg, msg)
+		if aggs == nil {
+			aggs = make(map[aggKey]*aggregate)
+		}
+		aggs[k] = a
+		aggKeys = append(aggKeys, k)
+	}
+	a.count++
+	a.sum += msg.Value
+	if msg.Value < a.min {
+		a.min = msg.Value
+	}
+	if msg.Value > a.max {
+		a.max = msg.Value
+	}
+	aggMu.Unlock()
+	return true
+}
+
+// takeAggregates returns the messages of the aggregated metrics and resets
+// the aggregates. It must be called with aggMu locked.
+func takeAggregates() []*Message {
+	if len(aggKeys) == 0 {
+		return nil
+	}
+
+	msgs := make([]*Message, len(aggKeys))
+	for i, k := range aggKeys {
+		a := aggs[k]
+		m := a.msg
+		m.Data = append(m.Data, KVPair{Key: "count", Value: a.count})
+		if m.Type == TypeEvent {
+			m.Value = a.sum
+		} else {
+			m.Value = a.sum / float64(a.count)
+			m.Data = append(m.Data, KVPair{Key: "min", Value: a.min}, KVPair{Key: "max", Value: a.max})
+		}
+		msgs[i] = m
+	}
+	aggs = nil
+	aggKeys = nil
+	return msgs
+}
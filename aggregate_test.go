@@ -0,0 +1,122 @@
+package say
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"gopkg.in/say.v0/sayclock"
+)
+
+func TestAggregation(t *testing.T) {
+	expect(t, func() {
+		SetAggregation(time.Hour)
+		defer SetAggregation(0)
+		for i := 0; i < 3; i++ {
+			Event("hit", "i", i)
+		}
+		Events("hit", 5)
+		Value("size", 2)
+		ValueUnit("size", 6, "B")
+		Value("size", 10)
+		Gauge("conns", 3)
+		Info("foo")
+		Value("size", "big")
+		FlushAggregates()
+		Event("miss")
+		Flush()
+		Event("miss")
+	}, []string{
+		"GAUGE conns:3",
+		"INFO  foo",
+		"VALUE size:big",
+		"EVENT hit:8	| i=0 count=4",
+		"VALUE size:6	| count=2 min=2 max=10",
+		"VALUE size:6B	| count=1 min=6 max=6",
+		"EVENT miss	| count=1",
+		"EVENT miss	| count=1",
+	})
+}
+
+func TestAggregationTenants(t *testing.T) {
+	buf := new(bytes.Buffer)
+	expect(t, func() {
+		SetAggregation(time.Hour)
+		defer SetAggregation(0)
+		acme := ForTenant("acme")
+		acme.Event("hit")
+		ForTenant("globex").Events("hit", 5)
+		acme.Event("hit")
+		out := NewLogger(WithOutput(buf))
+		out.Event("hit")
+		Event("hit")
+		FlushAggregates()
+	}, []string{
+		`EVENT hit:2	| tenant="acme" count=2`,
+		`EVENT hit:5	| tenant="globex" count=1`,
+		`EVENT hit	| count=1`,
+	})
+	if got, want := buf.String(), "EVENT hit	| count=1\n"; got != want {
+		t.Errorf("invalid Logger output, got %q, want %q", got, want)
+	}
+}
+
+func TestAggregationInterval(t *testing.T) {
+	c := sayclock.NewFake(time.Date(2015, 9, 1, 21, 37, 0, 0, time.UTC))
+	SetClock(c)
+	defer SetClock(sayclock.Real)
+
+	msgs := make(chan string, 10)
+	SetListener(func(m *Message) {
+		buf := getBuffer()
+		buf.appendMessage(m)
+		msgs <- buf.String()
+	})
+	defer SetListener(nil)
+	SetAggregation(time.Minute)
+	defer SetAggregation(0)
+
+	receive := func(want string) {
+		t.Helper()
+		select {
+		case got := <-msgs:
+			if got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no message received, want %q", want)
+		}
+	}
+
+	Event("hit")
+	Event("hit")
+	c.Add(30 * time.Second)
+	Event("hit")
+	c.Add(30 * time.Second)
+	receive("EVENT hit:3	| count=3\n")
+
+	// The last interval is flushed even if no metric follows it.
+	Value("size", 4)
+	c.Add(time.Minute)
+	receive("VALUE size:4	| count=1 min=4 max=4\n")
+}
+
+func TestFlushAggregatesOnExit(t *testing.T) {
+	var code int
+	exit = func(c int) { code = c }
+	defer func() { exit = func(int) {} }()
+
+	expect(t, func() {
+		SetAggregation(time.Hour)
+		defer SetAggregation(0)
+		Event("foo")
+		Event("foo")
+		FatalExit(3, "oops")
+	}, []string{
+		"FATAL oops",
+		"EVENT foo:2	| count=2",
+	})
+	if code != 3 {
+		t.Errorf("exit code = %d, want 3", code)
+	}
+}
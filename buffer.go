@@ -2,8 +2,11 @@ package say
 
 import (
 	"fmt"
+	"math"
+	"sort"
 	"strconv"
 	"sync"
+	"time"
 	"unicode/utf8"
 )
 
@@ -44,6 +47,15 @@ func (b *buffer) appendFloat64(f float64) {
 	b.buf = strconv.AppendFloat(b.buf, f, 'g', -1, 64)
 }
 
+// appendNumber appends f without exponent when f is an integer.
+func (b *buffer) appendNumber(f float64) {
+	if f == math.Trunc(f) && f <= maxExactInt && f >= -maxExactInt {
+		b.appendInt(int64(f))
+		return
+	}
+	b.appendFloat64(f)
+}
+
 func (b *buffer) appendFloat32(f float32) {
 	b.buf = strconv.AppendFloat(b.buf, float64(f), 'g', -1, 32)
 }
@@ -68,10 +80,6 @@ func (b *buffer) appendBool(v bool) {
 	b.appendString("false")
 }
 
-func (b *buffer) appendQuote(s string) {
-	b.buf = strconv.AppendQuote(b.buf, s)
-}
-
 func (b *buffer) appendEscapeByte(c byte) {
 	switch c {
 	case '\n':
@@ -87,7 +95,91 @@ func (b *buffer) appendEscapeString(s string) {
 	}
 }
 
+// appendMessage appends m as it is printed to the output.
+func (b *buffer) appendMessage(m *Message) {
+	b.appendString(string(m.Type))
+	b.appendByte(' ')
+	b.appendContent(m, true)
+	b.appendStackTrace(m, true)
+	b.appendMessageData(m)
+	b.appendByte('\n')
+}
+
+// appendStackTrace appends the stack trace of m, if any, after an empty line.
+// Newlines are escaped if escape is true.
+func (b *buffer) appendStackTrace(m *Message, escape bool) {
+	st := m.StackTrace()
+	if st == "" {
+		return
+	}
+	if escape {
+		b.appendEscapeString("\n\n")
+		b.appendEscapeString(st)
+	} else {
+		b.appendString("\n\n")
+		b.appendString(st)
+	}
+}
+
+// appendMessageData appends the Data of m in the format of the Logger which
+// sent it.
+func (b *buffer) appendMessageData(m *Message) {
+	if m.jsonData {
+		b.appendJSONData(m.Data)
+	} else {
+		b.appendData(m.Data)
+	}
+}
+
+// appendContent appends the content of m: the key and the value of a metric or
+// the text of a log message. Newlines are escaped if escape is true.
+func (b *buffer) appendContent(m *Message, escape bool) {
+	if m.Key != "" {
+		b.appendKey(m.Key)
+		if m.isNumeric() {
+			if m.hasDefaultIncrement() {
+				return
+			}
+			b.appendByte(':')
+			b.appendNumber(m.Value)
+			b.appendString(m.Unit)
+			return
+		}
+		if m.Content == "" {
+			return
+		}
+		b.appendByte(':')
+	}
+
+	if escape {
+		b.appendEscapeString(m.Content)
+	} else {
+		b.appendString(m.Content)
+	}
+}
+
+// appendKey appends key with the ':', '=' and '\' characters escaped with a
+// backslash so that they are not mistaken for separators.
+func (b *buffer) appendKey(key string) {
+	for i := 0; i < len(key); i++ {
+		switch c := key[i]; c {
+		case ':', '=', '\\':
+			b.buf = append(b.buf, '\\', c)
+		default:
+			b.buf = append(b.buf, c)
+		}
+	}
+}
+
 func (b *buffer) appendValue(v interface{}) {
+	if enc := encoderOf(v); enc != nil {
+		b.appendEncoded(enc, v)
+		return
+	}
+	if b.appendBuiltin(v) {
+		return
+	}
+
 	switch t := v.(type) {
 	case string:
 		b.appendString(t)
@@ -97,6 +189,10 @@ func (b *buffer) appendValue(v interface{}) {
 		b.appendString(t.Error())
 	case fmt.Stringer:
 		b.appendString(t.String())
+	case func() string:
+		b.appendString(callStringHook("", t))
+	case func() interface{}:
+		b.appendValue(callHook("", t))
 	case int:
 		b.appendInt(int64(t))
 	case int64:
@@ -124,21 +220,67 @@ func (b *buffer) appendValue(v interface{}) {
 	}
 }
 
-func (b *buffer) appendDataValue(v interface{}) bool {
+// appendDataValue appends the value of the data pair of the given key. It
+// reports whether a value has been appended. If json is true, the value is
+// appended in the JSON format.
+func (b *buffer) appendDataValue(key string, v interface{}, json bool) bool {
+	if enc := encoderOf(v); enc != nil {
+		b.appendQuoteEncoded(enc, v, json)
+		return true
+	}
+
 	switch t := v.(type) {
 	case string:
-		b.appendQuoteString(t)
+		b.appendQuoteValue(t, json)
 	case error:
-		b.appendQuoteString(t.Error())
+		b.appendQuoteValue(t.Error(), json)
+	case time.Time:
+		if json {
+			b.appendByte('"')
+		}
+		b.buf = t.AppendFormat(b.buf, time.RFC3339Nano)
+		if json {
+			b.appendByte('"')
+		}
+	case time.Duration:
+		if json {
+			b.appendByte('"')
+		}
+		b.appendString(t.String())
+		if json {
+			b.appendByte('"')
+		}
 	case fmt.Stringer:
-		b.appendQuoteString(t.String())
+		b.appendQuoteValue(t.String(), json)
 	case func() string:
-		b.appendQuoteString(t())
+		b.appendQuoteValue(callStringHook(key, t), json)
 	case Hook:
-		if v := t(); v != nil {
-			return b.appendDataValue(v)
+		if v := callHook(key, t); v != nil {
+			return b.appendDataValue(key, v, json)
 		}
 		return false
+	case []string:
+		b.appendByte('[')
+		for i, s := range t {
+			if i > 0 {
+				b.appendByte(',')
+			}
+			b.appendJSONString(s)
+		}
+		b.appendByte(']')
+	case []int:
+		b.appendByte('[')
+		for i, n := range t {
+			if i > 0 {
+				b.appendByte(',')
+			}
+			b.appendInt(int64(n))
+		}
+		b.appendByte(']')
+	case map[string]interface{}:
+		b.appendMap(t)
+	case Data:
+		b.appendGroup(t)
 	case int:
 		b.appendInt(int64(t))
 	case uint:
@@ -162,15 +304,70 @@ func (b *buffer) appendDataValue(v interface{}) bool {
 	case bool:
 		b.appendBool(t)
 	case float64:
-		b.appendFloat64(t)
+		if json && !isFinite(t) {
+			b.appendString("null")
+		} else {
+			b.appendFloat64(t)
+		}
 	case float32:
-		b.appendFloat32(t)
+		if json && !isFinite(float64(t)) {
+			b.appendString("null")
+		} else {
+			b.appendFloat32(t)
+		}
 	default:
-		b.appendQuoteString(fmt.Sprint(v))
+		b.appendQuoteValue(fmt.Sprint(v), json)
 	}
 	return true
 }
 
+// appendGroup appends the data of a group as a JSON object. When a key is set
+// several times, the last value is used.
+func (b *buffer) appendGroup(data Data) {
+	b.appendByte('{')
+	written := false
+	for i, kv := range data {
+		if data.isOverridden(i) {
+			continue
+		}
+		n := len(b.buf)
+		if written {
+			b.appendByte(',')
+		}
+		b.appendJSONString(kv.Key)
+		b.appendByte(':')
+		if b.appendDataValue(kv.Key, kv.Value, true) {
+			written = true
+		} else {
+			b.buf = b.buf[:n]
+		}
+	}
+	b.appendByte('}')
+}
+
+// appendMap appends m as a JSON object with sorted keys.
+func (b *buffer) appendMap(m map[string]interface{}) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	b.appendByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			b.appendByte(',')
+		}
+		b.appendJSONString(k)
+		b.appendByte(':')
+		n := len(b.buf)
+		if m[k] == nil || !b.appendDataValue(k, m[k], true) {
+			b.buf = append(b.buf[:n], "null"...)
+		}
+	}
+	b.appendByte('}')
+}
+
 func (b *buffer) Write(p []byte) (int, error) {
 	b.appendBytes(p)
 	return len(p), nil
@@ -200,22 +397,71 @@ func (b *buffer) appendData(data Data) {
 	start := len(b.buf)
 
 	b.appendString("\t|")
+	if !b.appendDataPairs(data, "") {
+		b.buf = b.buf[:start]
+	}
+}
+
+// appendDataPairs appends the key=value pairs of data, prefixing the keys with
+// prefix. The pairs of a group are appended with the name of the group and a
+// dot as prefix. It reports whether a pair has been appended.
+func (b *buffer) appendDataPairs(data Data, prefix string) bool {
 	written := false
 	for _, kv := range data {
+		if g, ok := kv.Value.(Data); ok {
+			if b.appendDataPairs(g, prefix+kv.Key+".") {
+				written = true
+			}
+			continue
+		}
+
 		i := len(b.buf)
 		b.appendByte(' ')
-		b.appendString(kv.Key)
+		b.appendKey(prefix)
+		b.appendKey(kv.Key)
 		b.appendByte('=')
-		if ok := b.appendDataValue(kv.Value); ok {
+		if ok := b.appendDataValue(prefix+kv.Key, kv.Value, false); ok {
 			written = true
 		} else {
 			b.buf = b.buf[:i]
 		}
 	}
+	return written
+}
+
+// appendJSONData appends data as a JSON object. When a key is set several
+// times, the last value is used.
+func (b *buffer) appendJSONData(data Data) {
+	if len(data) == 0 {
+		return
+	}
+
+	start := len(b.buf)
+
+	b.appendString("\t| {")
+	written := false
+	for i, kv := range data {
+		if data.isOverridden(i) {
+			continue
+		}
+		n := len(b.buf)
+		if written {
+			b.appendByte(',')
+		}
+		b.appendJSONString(kv.Key)
+		b.appendByte(':')
+		if ok := b.appendDataValue(kv.Key, kv.Value, true); ok {
+			written = true
+		} else {
+			b.buf = b.buf[:n]
+		}
+	}
 
 	if !written {
 		b.buf = b.buf[:start]
+		return
 	}
+	b.appendByte('}')
 }
 
 const (
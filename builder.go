@@ -0,0 +1,56 @@
+package say
+
+// A DataBuilder builds a list of key-value pairs that can be reused across
+// messages. Hot paths can keep one per goroutine or per connection and reset
+// it instead of passing new key-value pairs, and allocating them, for every
+// message:
+//
+//	var b say.DataBuilder
+//	for req := range requests {
+//		b.Reset().Add("method", req.Method).Add("path", req.Path)
+//		say.Info("Request", &b)
+//	}
+//
+// A *DataBuilder is passed in place of a key-value pair to the functions
+// accepting data. A DataBuilder must not be used concurrently.
+type DataBuilder struct {
+	data Data
+	err  error
+}
+
+// Reset removes the key-value pairs of the builder and keeps its memory for
+// the next pairs.
+func (b *DataBuilder) Reset() *DataBuilder {
+	for i := range b.data {
+		b.data[i] = KVPair{}
+	}
+	b.data = b.data[:0]
+	b.err = nil
+	return b
+}
+
+// Add adds a key-value pair to the builder. If the key is invalid, the error
+// is reported when the builder is used.
+func (b *DataBuilder) Add(key string, value interface{}) *DataBuilder {
+	key, err := checkKey(key)
+	if err != nil {
+		if b.err == nil {
+			b.err = err
+		}
+		return b
+	}
+	b.data = append(b.data, KVPair{Key: key, Value: filterDataValue(key, value)})
+	return b
+}
+
+// Attach returns a new Logger created from l that prints the key-value pairs
+// of the builder along with all its messages. The pairs are copied so that
+// the builder can be reset afterwards. It panics if a key is invalid.
+func (b *DataBuilder) Attach(l *Logger) *Logger {
+	if b.err != nil {
+		panic(b.err)
+	}
+	log := l.NewLogger()
+	log.data = append(log.data, b.data...)
+	return log
+}
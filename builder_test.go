@@ -0,0 +1,49 @@
+package say
+
+import "testing"
+
+func TestDataBuilder(t *testing.T) {
+	var b DataBuilder
+	expect(t, func() {
+		b.Add("a", 1).Add("b", "c")
+		Info("foo", &b, "d", true)
+		log := b.Attach(NewLogger())
+		b.Reset().Add("e", 2)
+		Event("bar", &b)
+		log.Info("baz")
+		b.Reset().Add("", 1).Add("f", 3)
+		Info("qux", &b)
+	}, []string{
+		`INFO  foo	| a=1 b="c" d=true`,
+		`EVENT bar	| e=2`,
+		`INFO  baz	| a=1 b="c"`,
+		`ERROR say: key is empty`,
+		`INFO  qux`,
+	})
+
+	defer func() {
+		if err := recover(); err != errKeyEmpty {
+			t.Errorf("Attach() = %v, want %v", err, errKeyEmpty)
+		}
+	}()
+	b.Attach(NewLogger())
+}
+
+func TestDataBuilderAllocs(t *testing.T) {
+	SetListener(func(*Message) {})
+	defer SetListener(nil)
+
+	var b DataBuilder
+	log := new(Logger)
+	send := func() {
+		b.Reset().Add("a", "b").Add("i", 42)
+		log.Info("foo", &b)
+		Flush()
+	}
+	send() // Fill the pools.
+
+	n := testing.AllocsPerRun(100, send)
+	if n > 1 {
+		t.Errorf("sending data with a DataBuilder allocates %v times, want at most 1", n)
+	}
+}
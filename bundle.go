@@ -0,0 +1,125 @@
+package say
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"runtime"
+	rdebug "runtime/debug"
+	"sync/atomic"
+)
+
+// WriteSupportBundle writes to w a zip archive with the information useful to
+// diagnose a problem, to be attached to a bug report:
+//
+//	config.txt      the settings of Say
+//	queue.txt       the state of the listener queue
+//	costs.txt       the cost report, if cost accounting is on
+//	goroutines.txt  the stack traces of all the goroutines
+//	buildinfo.txt   the build information of the program
+func WriteSupportBundle(w io.Writer) error {
+	z := zip.NewWriter(w)
+	files := []struct {
+		name  string
+		write func(io.Writer)
+	}{
+		{"config.txt", writeConfig},
+		{"queue.txt", writeQueueStats},
+		{"costs.txt", writeCosts},
+		{"goroutines.txt", writeGoroutines},
+		{"buildinfo.txt", writeBuildInfo},
+	}
+	for _, f := range files {
+		fw, err := z.Create(f.name)
+		if err != nil {
+			return err
+		}
+		f.write(fw)
+	}
+	return z.Close()
+}
+
+// writeConfig writes the settings of Say, one per line.
+func writeConfig(w io.Writer) {
+	mu.RLock()
+	writeSetting(w, "stack_traces", "%v", defaultLogger.skipStackFrames >= 0)
+	writeSetting(w, "compact_stack_traces", "%v", compactStackTraces)
+	writeSetting(w, "defer_stack_traces", "%v", deferStackTraces)
+	writeSetting(w, "stack_trace_budget", "%d per %v", traceBudget, traceBudgetInterval)
+	writeSetting(w, "redactor", "%v", redactor != nil)
+	writeSetting(w, "max_content_size", "%d", maxContentSize)
+	writeSetting(w, "max_value_size", "%d", maxValueSize)
+	writeSetting(w, "print_timestamps", "%q", printLayout)
+	writeSetting(w, "color", "%d (colored = %v)", colorMode, colored)
+	mu.RUnlock()
+
+	writeSetting(w, "debug", "%v", DebugEnabled())
+	writeSetting(w, "listener", "%v", listener != nil)
+	writeSetting(w, "tee_output", "%v", teeOutput)
+	writeSetting(w, "record_gauges", "%v", atomic.LoadInt32(&gaugesOn) == 1)
+	writeSetting(w, "disabled_types", "%#x", atomic.LoadUint32(&disabledTypes))
+	writeSetting(w, "key_policy", "%d", keyPolicy)
+	writeSetting(w, "key_normalization", "%v", keyNormalization)
+	writeSetting(w, "error_chain", "%d", errorChain)
+	writeSetting(w, "bytes_encoding", "%d", bytesEncoding)
+	writeSetting(w, "recover_hook_panics", "%v", recoverHookPanics)
+	writeSetting(w, "fatal_exits", "%v", fatalExits)
+	writeSetting(w, "trace_regions", "%v", traceRegions)
+	writeSetting(w, "time_format", "%q", timeLayout)
+	writeSetting(w, "time_location", "%v", timeLocation)
+	writeSetting(w, "queue_memory", "%d", atomic.LoadInt64(&queueMaxBytes))
+	writeSetting(w, "cost_accounting", "%v", isCostAccountingEnabled())
+
+	quotaMu.Lock()
+	writeSetting(w, "tenant_quota", "%d per %v", quotaMax, quotaInterval)
+	quotaMu.Unlock()
+	aggMu.Lock()
+	writeSetting(w, "aggregation", "%v", aggInterval)
+	aggMu.Unlock()
+	summaryMu.Lock()
+	writeSetting(w, "error_summary", "top %d every %v", summaryTop, summaryInterval)
+	summaryMu.Unlock()
+	rateMu.Lock()
+	writeSetting(w, "rate_limits", "%d", len(rateLimits))
+	rateMu.Unlock()
+}
+
+// writeSetting writes the setting key. The settings that cannot be changed with
+// SetConfig are commented out so that LoadConfigFile ignores them.
+func writeSetting(w io.Writer, key, format string, a ...interface{}) {
+	if _, ok := settings[key]; !ok {
+		io.WriteString(w, "# ")
+	}
+	fmt.Fprintf(w, key+" = "+format+"\n", a...)
+}
+
+func writeQueueStats(w io.Writer) {
+	var queued int
+	if listener != nil {
+		queued = len(ch)
+	}
+	writeSetting(w, "messages", "%d", queued)
+	writeSetting(w, "bytes", "%d", atomic.LoadInt64(&queueBytes))
+	writeSetting(w, "dropped", "%d", atomic.LoadInt64(&queueDropped))
+}
+
+func writeCosts(w io.Writer) {
+	for _, c := range CostReport() {
+		fmt.Fprintf(w, "%s\tcount=%d duration=%v bytes=%d\n",
+			c.CallSite, c.Count, c.Duration, c.Bytes)
+	}
+}
+
+func writeGoroutines(w io.Writer) {
+	buf := make([]byte, 1<<20)
+	w.Write(buf[:runtime.Stack(buf, true)])
+}
+
+func writeBuildInfo(w io.Writer) {
+	info, ok := rdebug.ReadBuildInfo()
+	if !ok {
+		fmt.Fprintln(w, "no build information")
+		return
+	}
+	fmt.Fprint(w, info)
+}
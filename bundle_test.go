@@ -0,0 +1,50 @@
+package say
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestWriteSupportBundle(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := WriteSupportBundle(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	z, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	for _, f := range z.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = string(b)
+	}
+
+	want := map[string]string{
+		"config.txt":     "stack_traces = false\n",
+		"queue.txt":      "messages = 0\n",
+		"costs.txt":      "",
+		"goroutines.txt": "TestWriteSupportBundle",
+		"buildinfo.txt":  "",
+	}
+	for name, s := range want {
+		content, ok := files[name]
+		if !ok {
+			t.Errorf("the bundle has no %s file", name)
+		} else if !strings.Contains(content, s) {
+			t.Errorf("%s does not contain %q:\n%s", name, s, content)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package say
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strconv"
+)
+
+// A BytesEncoding is the way the []byte data values are written.
+type BytesEncoding int
+
+// The encodings of []byte data values.
+const (
+	// BytesHex writes the bytes in hexadecimal. It is the default.
+	BytesHex BytesEncoding = iota
+	// BytesBase64 writes the bytes in standard base64.
+	BytesBase64
+	// BytesLength only writes the number of bytes, e.g. "42 bytes".
+	BytesLength
+)
+
+var bytesEncoding = BytesHex
+
+// SetBytesEncoding sets the encoding of the []byte data values. Use
+// Data.GetBytes to decode them.
+//
+// This function must not be called concurrently with the other functions of
+// this package.
+func SetBytesEncoding(enc BytesEncoding) {
+	bytesEncoding = enc
+}
+
+// encodeBytes returns b encoded with the encoding set with SetBytesEncoding.
+func encodeBytes(b []byte) string {
+	switch bytesEncoding {
+	case BytesBase64:
+		return base64.StdEncoding.EncodeToString(b)
+	case BytesLength:
+		return strconv.Itoa(len(b)) + " bytes"
+	default:
+		return hex.EncodeToString(b)
+	}
+}
+
+// GetBytes gets the value associated with the given key as a []byte. The
+// value is decoded from a string with enc, which must be the encoding used
+// when the message was sent. BytesLength values cannot be decoded. If the given
+// key does not exist or its value cannot be decoded, ok is false.
+func (d Data) GetBytes(key string, enc BytesEncoding) (b []byte, ok bool) {
+	v, ok := d.Get(key)
+	if !ok {
+		return nil, false
+	}
+	switch v := v.(type) {
+	case []byte:
+		return v, true
+	case string:
+		var err error
+		switch enc {
+		case BytesHex:
+			b, err = hex.DecodeString(v)
+		case BytesBase64:
+			b, err = base64.StdEncoding.DecodeString(v)
+		default:
+			return nil, false
+		}
+		return b, err == nil
+	}
+	return nil, false
+}
@@ -0,0 +1,53 @@
+package say
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSetBytesEncoding(t *testing.T) {
+	defer SetBytesEncoding(BytesHex)
+
+	p := []byte("\x00say\xff")
+	expect(t, func() {
+		Info("foo", "p", p)
+		SetBytesEncoding(BytesBase64)
+		Info("foo", "p", p)
+		SetBytesEncoding(BytesLength)
+		Info("foo", "p", p)
+	}, []string{
+		`INFO  foo	| p="00736179ff"`,
+		`INFO  foo	| p="AHNhef8="`,
+		`INFO  foo	| p="5 bytes"`,
+	})
+}
+
+func TestDataGetBytes(t *testing.T) {
+	d := Data{
+		{"hex", "00736179ff"},
+		{"base64", "AHNhef8="},
+		{"raw", []byte("\x00say\xff")},
+		{"length", "5 bytes"},
+	}
+	want := []byte("\x00say\xff")
+
+	tests := []struct {
+		key string
+		enc BytesEncoding
+		ok  bool
+	}{
+		{"hex", BytesHex, true},
+		{"base64", BytesBase64, true},
+		{"raw", BytesHex, true},
+		{"length", BytesLength, false},
+		{"base64", BytesHex, false},
+		{"foo", BytesHex, false},
+	}
+	for _, tt := range tests {
+		got, ok := d.GetBytes(tt.key, tt.enc)
+		if ok != tt.ok || ok && !bytes.Equal(got, want) {
+			t.Errorf("Data.GetBytes(%q, %d) = (%q, %v), want (%q, %v)",
+				tt.key, tt.enc, got, ok, want, tt.ok)
+		}
+	}
+}
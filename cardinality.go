@@ -0,0 +1,65 @@
+package say
+
+import (
+	"errors"
+	"hash/fnv"
+	"strconv"
+)
+
+var errBucketCount = errors.New("say: HashMetricData needs a positive number of buckets")
+
+// StripMetricData returns a listener that removes the data pairs of the given
+// keys from the metrics (EVENT, VALUE, GAUGE, HISTO and UNIQ messages) and
+// then calls f. The other messages are passed unchanged. Use it to keep
+// high-cardinality values, such as request IDs, in the logs without exploding
+// the number of metric series:
+//
+//	say.SetListener(say.StripMetricData(listener, "request_id", "ip"))
+func StripMetricData(f func(*Message), keys ...string) func(*Message) {
+	set := keySet(keys)
+	return func(m *Message) {
+		if m.isMetric() {
+			data := m.Data[:0]
+			for _, kv := range m.Data {
+				if !set[kv.Key] {
+					data = append(data, kv)
+				}
+			}
+			m.Data = data
+		}
+		f(m)
+	}
+}
+
+// HashMetricData returns a listener that replaces the values of the data pairs
+// of the given keys of the metrics (EVENT, VALUE, GAUGE, HISTO and UNIQ
+// messages) by one of n buckets, chosen by hashing the value, and then calls
+// f. The other messages are passed unchanged. Unlike StripMetricData, the
+// metrics can still be split by these keys, with at most n series per key.
+// HashMetricData panics if n is not positive.
+func HashMetricData(f func(*Message), n int, keys ...string) func(*Message) {
+	if n <= 0 {
+		panic(errBucketCount)
+	}
+	set := keySet(keys)
+	return func(m *Message) {
+		if m.isMetric() {
+			for i, kv := range m.Data {
+				if set[kv.Key] {
+					m.Data[i].Value = hashBucket(kv.Value, n)
+				}
+			}
+		}
+		f(m)
+	}
+}
+
+// hashBucket returns the bucket of v among n buckets.
+func hashBucket(v interface{}, n int) string {
+	buf := getBuffer()
+	buf.appendValue(v)
+	h := fnv.New32a()
+	h.Write(buf.buf)
+	putBuffer(buf)
+	return "bucket_" + strconv.Itoa(int(h.Sum32()%uint32(n)))
+}
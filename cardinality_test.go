@@ -0,0 +1,48 @@
+package say
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStripMetricData(t *testing.T) {
+	var got []string
+	f := func(m *Message) {
+		got = append(got, toString(m.Data))
+	}
+
+	SetListener(StripMetricData(f, "id", "ip"))
+	Event("foo", "id", 1, "a", 2, "ip", "10.0.0.1")
+	Info("foo", "id", 1, "a", 2)
+	Flush()
+
+	SetListener(HashMetricData(f, 4, "id"))
+	Value("foo", 3, "id", 1, "a", 2)
+	Value("foo", 3, "id", 1, "a", 2)
+	Info("foo", "id", 1)
+	Flush()
+	SetListener(nil)
+
+	if len(got) != 5 {
+		t.Fatalf("the listener received %d messages, want 5", len(got))
+	}
+	if want := `(K:"a", V:'\x02')`; got[0] != want {
+		t.Errorf("StripMetricData: Data = %s, want %s", got[0], want)
+	}
+	if want := `(K:"id", V:'\x01'),(K:"a", V:'\x02')`; got[1] != want {
+		t.Errorf("StripMetricData: Data = %s, want %s", got[1], want)
+	}
+	if !strings.Contains(got[2], `V:"bucket_`) || got[2] != got[3] {
+		t.Errorf("HashMetricData: Data = %s and %s, want the same bucket", got[2], got[3])
+	}
+	if want := `(K:"id", V:'\x01')`; got[4] != want {
+		t.Errorf("HashMetricData: Data = %s, want %s", got[4], want)
+	}
+
+	defer func() {
+		if err := recover(); err != errBucketCount {
+			t.Errorf("HashMetricData(0) = %v, want %v", err, errBucketCount)
+		}
+	}()
+	HashMetricData(f, 0, "id")
+}
@@ -0,0 +1,120 @@
+package say
+
+import (
+	"io"
+	"os"
+)
+
+// A ColorMode tells whether the messages printed to the output are colored.
+type ColorMode int
+
+// The color modes.
+const (
+	// ColorNever never colors the output. It is the default.
+	ColorNever ColorMode = iota
+	// ColorAuto colors the output when it is a terminal and the NO_COLOR
+	// environment variable is not set.
+	ColorAuto
+	// ColorAlways always colors the output.
+	ColorAlways
+)
+
+var (
+	colorMode ColorMode
+	colored   bool
+)
+
+// SetColor sets whether the messages printed to the output are colored with
+// ANSI escape codes, for readability during local development. The type of the
+// messages is colored by severity, the data is dimmed and the stack traces are
+// red.
+//
+// It is only effective when SetListener has not been used and does not affect
+// the Loggers created with the WithOutput option.
+func SetColor(mode ColorMode) {
+	mu.Lock()
+	colorMode = mode
+	updateColored()
+	mu.Unlock()
+}
+
+// updateColored updates colored according to the color mode and the output.
+// It must be called with mu held.
+func updateColored() {
+	switch colorMode {
+	case ColorAlways:
+		colored = true
+	case ColorAuto:
+		colored = os.Getenv("NO_COLOR") == "" && isTerminal(out)
+	default:
+		colored = false
+	}
+}
+
+// isTerminal reports whether w is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// The ANSI escape codes.
+const (
+	colorReset   = "\x1b[0m"
+	colorDim     = "\x1b[2m"
+	colorRed     = "\x1b[31m"
+	colorBoldRed = "\x1b[1;31m"
+	colorGreen   = "\x1b[32m"
+	colorYellow  = "\x1b[33m"
+	colorMagenta = "\x1b[35m"
+	colorCyan    = "\x1b[36m"
+	colorGray    = "\x1b[90m"
+)
+
+func typeColor(typ Type) string {
+	switch typ {
+	case TypeDebug:
+		return colorGray
+	case TypeInfo, TypeSummary:
+		return colorCyan
+	case TypeWarning:
+		return colorYellow
+	case TypeError:
+		return colorRed
+	case TypeFatal:
+		return colorBoldRed
+	case TypeUser, TypeAudit:
+		return colorMagenta
+	default:
+		return colorGreen
+	}
+}
+
+// appendColorMessage appends m as it is printed to the output, colored with
+// ANSI escape codes.
+func (b *buffer) appendColorMessage(m *Message) {
+	b.appendString(typeColor(m.Type))
+	b.appendString(string(m.Type))
+	b.appendString(colorReset)
+	b.appendByte(' ')
+
+	b.appendContent(m, true)
+	if m.StackTrace() != "" {
+		b.appendString(colorRed)
+		b.appendStackTrace(m, true)
+		b.appendString(colorReset)
+	}
+
+	n := len(b.buf)
+	b.appendString(colorDim)
+	b.appendMessageData(m)
+	if len(b.buf) == n+len(colorDim) {
+		b.buf = b.buf[:n]
+	} else {
+		b.appendString(colorReset)
+	}
+	b.appendByte('\n')
+}
@@ -0,0 +1,28 @@
+package say
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSetColor(t *testing.T) {
+	defer SetColor(ColorNever)
+
+	expect(t, func() {
+		SetColor(ColorAlways)
+		Info("foo", "a", 1)
+		Event("foo")
+		Error("foo", "a", 1)
+		SetColor(ColorAuto)
+		Info("foo", "a", 1)
+	}, []string{
+		"\x1b[36mINFO \x1b[0m foo\x1b[2m\t| a=1\x1b[0m",
+		"\x1b[32mEVENT\x1b[0m foo",
+		"\x1b[31mERROR\x1b[0m foo\x1b[2m\t| a=1\x1b[0m",
+		"INFO  foo\t| a=1",
+	})
+
+	if isTerminal(new(bytes.Buffer)) {
+		t.Error("isTerminal(bytes.Buffer) = true, want false")
+	}
+}
@@ -0,0 +1,153 @@
+package say
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var errUnknownSetting = errors.New("say: unknown setting")
+
+// A setting sets a setting from its string value.
+type setting struct {
+	set    func(string) error
+	isBool bool
+}
+
+// settings are the settings that can be changed with SetConfig.
+var settings = map[string]setting{
+	"debug":                boolSetting(SetDebug),
+	"stack_traces":         boolSetting(func(b bool) { DisableStackTraces(!b) }),
+	"compact_stack_traces": boolSetting(CompactStackTraces),
+	"defer_stack_traces":   boolSetting(DeferStackTraces),
+	"key_normalization":    boolSetting(SetKeyNormalization),
+	"fatal_exits":          boolSetting(FatalExits),
+	"tee_output":           boolSetting(TeeOutput),
+	"record_gauges":        boolSetting(RecordGauges),
+	"trace_regions":        boolSetting(TraceRegions),
+	"cost_accounting":      boolSetting(SetCostAccounting),
+	"queue_memory": {set: func(v string) error {
+		n, err := strconv.Atoi(v)
+		if err == nil {
+			SetQueueMemory(n)
+		}
+		return err
+	}},
+	"time_format": {set: func(v string) error {
+		if s, err := strconv.Unquote(v); err == nil {
+			v = s
+		}
+		SetTimeFormat(v, timeLocation)
+		return nil
+	}},
+}
+
+func boolSetting(set func(bool)) setting {
+	return setting{
+		set: func(v string) error {
+			b, err := strconv.ParseBool(v)
+			if err == nil {
+				set(b)
+			}
+			return err
+		},
+		isBool: true,
+	}
+}
+
+// SetConfig changes the setting named key, as listed by EffectiveConfig. The
+// settings that can be changed are debug, stack_traces, compact_stack_traces,
+// defer_stack_traces, key_normalization, fatal_exits, tee_output,
+// record_gauges, trace_regions, cost_accounting, queue_memory and time_format.
+// The other settings listed by EffectiveConfig are read-only.
+//
+// Like the functions it calls, SetConfig must not be called concurrently with
+// the other functions of this package.
+func SetConfig(key, value string) error {
+	s, ok := settings[key]
+	if !ok {
+		return errUnknownSetting
+	}
+	return s.set(value)
+}
+
+// LoadConfigFile applies the settings of a file made of "key = value" lines.
+// Empty lines and lines starting with # are ignored.
+//
+// The settings are meant to be layered: defaults, then a file, then the
+// environment, then the command-line flags:
+//
+//	say.ConfigFlags(flag.CommandLine)
+//	if err := say.LoadConfigFile("say.conf"); err != nil { ... }
+//	if err := say.LoadConfigEnv(); err != nil { ... }
+//	flag.Parse()
+func LoadConfigFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		i := strings.IndexByte(line, '=')
+		if i == -1 {
+			return errors.New("say: invalid config line: " + line)
+		}
+		key := strings.TrimSpace(line[:i])
+		if err := SetConfig(key, strings.TrimSpace(line[i+1:])); err != nil {
+			return errors.New("say: invalid setting " + key + ": " + err.Error())
+		}
+	}
+	return s.Err()
+}
+
+// LoadConfigEnv applies the settings set in environment variables named after
+// the setting in upper case and prefixed with SAY_, e.g. SAY_DEBUG=true.
+func LoadConfigEnv() error {
+	for key := range settings {
+		v, ok := os.LookupEnv("SAY_" + strings.ToUpper(key))
+		if !ok {
+			continue
+		}
+		if err := SetConfig(key, v); err != nil {
+			return errors.New("say: invalid setting " + key + ": " + err.Error())
+		}
+	}
+	return nil
+}
+
+// ConfigFlags defines a flag for each setting in fs, named after the setting
+// and prefixed with "say.", e.g. -say.debug=true. The settings are applied when
+// fs is parsed.
+func ConfigFlags(fs *flag.FlagSet) {
+	for key, s := range settings {
+		fs.Var(settingFlag(s), "say."+key, "set the "+key+" setting of Say")
+	}
+}
+
+// settingFlag is a flag.Value setting a setting when the flag is parsed.
+type settingFlag setting
+
+func (f settingFlag) String() string     { return "" }
+func (f settingFlag) Set(v string) error { return f.set(v) }
+func (f settingFlag) IsBoolFlag() bool   { return f.isBool }
+
+// EffectiveConfig returns the settings in effect, one "key = value" per line.
+// Print it at startup to know which settings are active in a deployment.
+//
+// The read-only settings are commented out with a leading #, so that the
+// output can be loaded back with LoadConfigFile.
+func EffectiveConfig() string {
+	buf := new(bytes.Buffer)
+	writeConfig(buf)
+	return buf.String()
+}
@@ -0,0 +1,91 @@
+package say
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLayeredConfig(t *testing.T) {
+	defer SetDebug(false)
+	defer FatalExits(false)
+	defer SetQueueMemory(0)
+	defer CompactStackTraces(false)
+
+	dir, err := ioutil.TempDir("", "say")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "say.conf")
+	conf := "# Production settings\ndebug = true\nfatal_exits = true\nqueue_memory = 1000\n"
+	if err := ioutil.WriteFile(path, []byte(conf), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	ConfigFlags(fs)
+	if err := LoadConfigFile(path); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("SAY_QUEUE_MEMORY", "2000")
+	defer os.Unsetenv("SAY_QUEUE_MEMORY")
+	if err := LoadConfigEnv(); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Parse([]string{"-say.debug=false", "-say.compact_stack_traces"}); err != nil {
+		t.Fatal(err)
+	}
+
+	config := EffectiveConfig()
+	for _, s := range []string{
+		"debug = false\n",
+		"fatal_exits = true\n",
+		"queue_memory = 2000\n",
+		"compact_stack_traces = true\n",
+	} {
+		if !strings.Contains(config, s) {
+			t.Errorf("the effective config does not contain %q:\n%s", s, config)
+		}
+	}
+
+	if err := SetConfig("foo", "1"); err != errUnknownSetting {
+		t.Errorf("SetConfig(\"foo\") = %v, want %v", err, errUnknownSetting)
+	}
+	if err := SetConfig("debug", "maybe"); err == nil {
+		t.Error("SetConfig(\"debug\", \"maybe\") succeeded")
+	}
+}
+
+func TestEffectiveConfigLoad(t *testing.T) {
+	config := EffectiveConfig()
+	for _, s := range []string{
+		"\ntee_output = false\n",
+		"\nrecord_gauges = false\n",
+		"\n# redactor = false\n",
+		"\n# error_summary = top 0 every 0s\n",
+	} {
+		if !strings.Contains(config, s) {
+			t.Errorf("the effective config does not contain %q:\n%s", s[1:], config)
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "say")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "say.conf")
+	if err := ioutil.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadConfigFile(path); err != nil {
+		t.Errorf("LoadConfigFile(EffectiveConfig()) = %v", err)
+	}
+	if got := EffectiveConfig(); got != config {
+		t.Errorf("the effective config changed after loading it:\n%s\nwant:\n%s", got, config)
+	}
+}
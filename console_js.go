@@ -0,0 +1,35 @@
+//go:build js && wasm
+// +build js,wasm
+
+package say
+
+import "syscall/js"
+
+// ConsoleListener is a listener for WebAssembly programs running in a browser
+// that writes the messages to the browser console. DEBUG, INFO, WARN, ERROR
+// and FATAL messages are written with console.debug, console.info,
+// console.warn and console.error, the other messages with console.log:
+//
+//	say.SetListener(say.ConsoleListener)
+//
+// To send the messages to a collection endpoint instead, use a listener
+// posting them with net/http, which relies on the Fetch API.
+func ConsoleListener(m *Message) {
+	buf := getBuffer()
+	buf.appendMessage(m)
+	line := string(buf.buf[:len(buf.buf)-1]) // Remove the last newline.
+	putBuffer(buf)
+
+	method := "log"
+	switch m.Type {
+	case TypeDebug:
+		method = "debug"
+	case TypeInfo:
+		method = "info"
+	case TypeWarning:
+		method = "warn"
+	case TypeError, TypeFatal:
+		method = "error"
+	}
+	js.Global().Get("console").Call(method, line)
+}
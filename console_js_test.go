@@ -0,0 +1,50 @@
+//go:build js && wasm
+// +build js,wasm
+
+package say
+
+import (
+	"syscall/js"
+	"testing"
+)
+
+func TestConsoleListener(t *testing.T) {
+	console := js.Global().Get("console")
+	defer js.Global().Set("console", console)
+
+	var got []string
+	fake := js.Global().Get("Object").New()
+	for _, method := range []string{"log", "debug", "info", "warn", "error"} {
+		method := method
+		f := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			got = append(got, method+": "+args[0].String())
+			return nil
+		})
+		defer f.Release()
+		fake.Set(method, f)
+	}
+	js.Global().Set("console", fake)
+
+	SetListener(ConsoleListener)
+	Event("foo")
+	Info("foo", "a", 1)
+	Warning("foo")
+	Error("foo")
+	Flush()
+	SetListener(nil)
+
+	want := []string{
+		"log: EVENT foo",
+		"info: INFO  foo\t| a=1",
+		"warn: WARN  foo",
+		"error: ERROR foo",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("console received %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("console received %q, want %q", got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package say
+
+import (
+	"context"
+	"time"
+)
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the Logger l.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the Logger carried by ctx. If ctx carries no Logger, it
+// returns the package-level Logger.
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(contextKey{}).(*Logger); ok && l != nil {
+		return l
+	}
+	return defaultLogger
+}
+
+// ErrorContext prints an ERROR message with the stack trace, annotated with
+// the state of ctx: the time remaining before its deadline in milliseconds
+// (deadline_ms) and, if ctx is done, why it is (ctx_error and cause).
+func (l *Logger) ErrorContext(ctx context.Context, v interface{}, data ...interface{}) {
+	l.error(TypeError, v, contextData(ctx, data), 1)
+}
+
+// ErrorContext prints an ERROR message with the stack trace, annotated with
+// the state of ctx: the time remaining before its deadline in milliseconds
+// (deadline_ms) and, if ctx is done, why it is (ctx_error and cause).
+func ErrorContext(ctx context.Context, v interface{}, data ...interface{}) {
+	defaultLogger.ErrorContext(ctx, v, data...)
+}
+
+// contextData returns a copy of data with the key-value pairs describing the
+// state of ctx appended.
+func contextData(ctx context.Context, data []interface{}) []interface{} {
+	d := make([]interface{}, len(data), len(data)+6)
+	copy(d, data)
+
+	if deadline, ok := ctx.Deadline(); ok {
+		d = append(d, "deadline_ms", int64(time.Until(deadline)/time.Millisecond))
+	}
+	if err := ctx.Err(); err != nil {
+		d = append(d, "ctx_error", err)
+		if cause := context.Cause(ctx); cause != nil && cause != err {
+			d = append(d, "cause", cause)
+		}
+	}
+	return d
+}
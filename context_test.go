@@ -0,0 +1,53 @@
+package say
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gopkg.in/say.v0/sayclock"
+)
+
+func TestErrorContext(t *testing.T) {
+	expect(t, func() {
+		ErrorContext(context.Background(), "foo", "a", 1)
+
+		ctx, cancelCause := context.WithCancelCause(context.Background())
+		cancelCause(errors.New("shutting down"))
+		ErrorContext(ctx, "foo")
+	}, []string{
+		`ERROR foo	| a=1`,
+		`ERROR foo	| ctx_error="context canceled" cause="shutting down"`,
+	})
+}
+
+func TestContextDeadline(t *testing.T) {
+	// Context deadlines are wall-clock times: the clock set with SetClock
+	// must not be used to compute the remaining time.
+	SetClock(sayclock.NewFake(time.Date(2015, 9, 1, 21, 37, 0, 0, time.UTC)))
+	defer SetClock(sayclock.Real)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	d := contextData(ctx, nil)
+	if len(d) != 2 || d[0] != "deadline_ms" {
+		t.Fatalf("contextData() = %v, want deadline_ms", d)
+	}
+	if ms := d[1].(int64); ms <= 3590000 || ms > 3600000 {
+		t.Errorf("deadline_ms = %d, want about 3600000", ms)
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	expect(t, func() {
+		log := NewLogger()
+		log.AddData("request_id", 3)
+		ctx := NewContext(context.Background(), log)
+		FromContext(ctx).Info("foo")
+		FromContext(context.Background()).Info("bar")
+	}, []string{
+		"INFO  foo	| request_id=3",
+		"INFO  bar",
+	})
+}
@@ -0,0 +1,139 @@
+package say
+
+import (
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// A CallSiteCost is the cumulative cost of the messages sent from a call site.
+type CallSiteCost struct {
+	CallSite string // The file:line of the call.
+	Count    int    // The number of messages sent.
+	// Duration is the time spent formatting and printing the messages or
+	// sending them to the listener.
+	Duration time.Duration
+	Bytes    int // The size of the messages in the text format.
+}
+
+var (
+	costAccounting int32 // Accessed atomically.
+	costMu         sync.Mutex
+	costs          = make(map[string]*CallSiteCost)
+)
+
+// SetCostAccounting sets whether Say records the cost of sending messages per
+// call site. It is off by default. The costs are retrieved using CostReport.
+//
+// Cost accounting makes sending messages slower: use it to find the log
+// statements that are actually expensive, not in production.
+func SetCostAccounting(b bool) {
+	var v int32
+	if b {
+		v = 1
+	}
+	atomic.StoreInt32(&costAccounting, v)
+}
+
+func isCostAccountingEnabled() bool {
+	return atomic.LoadInt32(&costAccounting) == 1
+}
+
+// CostReport returns the costs recorded since cost accounting has been enabled
+// with SetCostAccounting, the most expensive call sites first.
+func CostReport() []CallSiteCost {
+	costMu.Lock()
+	report := make([]CallSiteCost, 0, len(costs))
+	for _, c := range costs {
+		report = append(report, *c)
+	}
+	costMu.Unlock()
+
+	sort.Sort(byDuration(report))
+	return report
+}
+
+// ResetCostReport clears the costs recorded so far.
+func ResetCostReport() {
+	costMu.Lock()
+	costs = make(map[string]*CallSiteCost)
+	costMu.Unlock()
+}
+
+type byDuration []CallSiteCost
+
+func (s byDuration) Len() int      { return len(s) }
+func (s byDuration) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byDuration) Less(i, j int) bool {
+	if s[i].Duration != s[j].Duration {
+		return s[i].Duration > s[j].Duration
+	}
+	return s[i].CallSite < s[j].CallSite
+}
+
+func addCost(d time.Duration, n int) {
+	site := callSite(0)
+
+	costMu.Lock()
+	c, ok := costs[site]
+	if !ok {
+		c = &CallSiteCost{CallSite: site}
+		costs[site] = c
+	}
+	c.Count++
+	c.Duration += d
+	c.Bytes += n
+	costMu.Unlock()
+}
+
+// funcPrefix is the prefix of the names of the functions of this package.
+var funcPrefix = getFuncPrefix()
+
+func getFuncPrefix() string {
+	pc, _, _, _ := runtime.Caller(0)
+	name := runtime.FuncForPC(pc).Name()
+	return name[:strings.LastIndexByte(name, '.')+1]
+}
+
+// callSite returns the file:line of the first caller outside of this package,
+// skipping skip more frames.
+func callSite(skip int) string {
+	var pcs [32]uintptr
+	n := runtime.Callers(2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		f, more := frames.Next()
+		if !isSayFrame(f) {
+			if skip == 0 {
+				return shortFile(f.File) + ":" + strconv.Itoa(f.Line)
+			}
+			skip--
+		}
+		if !more {
+			return "???"
+		}
+	}
+}
+
+// isSayFrame reports whether f is the frame of a function of this package.
+// Tests of this package are considered as callers.
+func isSayFrame(f runtime.Frame) bool {
+	return strings.HasPrefix(f.Function, funcPrefix) &&
+		!strings.HasSuffix(f.File, "_test.go")
+}
+
+// shortFile trims the file path to its parent directory and base name.
+func shortFile(file string) string {
+	i := strings.LastIndexByte(file, '/')
+	if i == -1 {
+		return file
+	}
+	if j := strings.LastIndexByte(file[:i], '/'); j != -1 {
+		return file[j+1:]
+	}
+	return file
+}
@@ -0,0 +1,45 @@
+package say
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCostReport(t *testing.T) {
+	w := Mute()
+	defer Redirect(w)
+
+	SetCostAccounting(true)
+	for i := 0; i < 3; i++ {
+		Info("foo")
+	}
+	NewLogger().Event("bar")
+	SetCostAccounting(false)
+	Info("baz")
+	defer ResetCostReport()
+
+	report := CostReport()
+	if len(report) != 2 {
+		t.Fatalf("len(CostReport()) = %d, want 2: %v", len(report), report)
+	}
+
+	var count, bytes int
+	for _, c := range report {
+		if !strings.Contains(c.CallSite, "/cost_test.go:") {
+			t.Errorf("CallSite = %q, want a line of cost_test.go", c.CallSite)
+		}
+		count += c.Count
+		bytes += c.Bytes
+	}
+	if count != 4 {
+		t.Errorf("total count = %d, want 4", count)
+	}
+	if want := 3*len("INFO  foo\n") + len("EVENT bar\n"); bytes != want {
+		t.Errorf("total bytes = %d, want %d", bytes, want)
+	}
+
+	ResetCostReport()
+	if report := CostReport(); len(report) != 0 {
+		t.Errorf("CostReport() = %v after reset, want empty", report)
+	}
+}
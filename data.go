@@ -1,6 +1,12 @@
 package say
 
-import "fmt"
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"time"
+)
 
 // Data is a list of key-value pairs associated with a message.
 type Data []KVPair
@@ -14,9 +20,12 @@ type KVPair struct {
 // SetData sets a key-value pair that will be printed along with all messages
 // sent with this Logger.
 func (l *Logger) SetData(data ...interface{}) {
+	// Do not reuse the previous array since it may be shared with child
+	// Loggers.
+	var d Data
+	err := d.appendData(data)
 	mu.Lock()
-	l.data = l.data[:0]
-	err := l.data.appendData(data)
+	l.data = d
 	mu.Unlock()
 	if err != nil {
 		panic(err)
@@ -32,12 +41,13 @@ func SetData(data ...interface{}) {
 // AddData adds a key-value pair that will be printed along with all messages
 // sent with this Logger.
 func (l *Logger) AddData(key string, value interface{}) {
-	if err := isKeyValid(key); err != nil {
+	key, err := checkKey(key)
+	if err != nil {
 		panic(err)
 	}
 
 	mu.Lock()
-	l.data = append(l.data, KVPair{Key: key, Value: filterDataValue(value)})
+	l.data = append(l.data, KVPair{Key: key, Value: filterDataValue(key, value)})
 	defer mu.Unlock()
 }
 
@@ -47,39 +57,218 @@ func AddData(key string, value interface{}) {
 	defaultLogger.AddData(key, value)
 }
 
-func (d *Data) appendData(data []interface{}) error {
-	if len(data)%2 != 0 {
-		return errOddNumArgs
+// Data returns a copy of the key-value pairs printed along with all messages
+// sent with this Logger, including the ones inherited from its parent.
+func (l *Logger) Data() Data {
+	mu.RLock()
+	d := append(Data(nil), l.data...)
+	mu.RUnlock()
+	return d
+}
+
+// RemoveData removes the key-value pairs of the given key from the pairs
+// printed along with all messages sent with this Logger. The Loggers created
+// from this Logger are not affected.
+func (l *Logger) RemoveData(key string) {
+	if k, err := checkKey(key); err == nil {
+		key = k
+	}
+
+	mu.Lock()
+	// Do not modify the previous array since it may be shared with child
+	// Loggers.
+	d := make(Data, 0, len(l.data))
+	for _, kv := range l.data {
+		if kv.Key != key {
+			d = append(d, kv)
+		}
+	}
+	l.data = d
+	mu.Unlock()
+}
+
+// RemoveData removes the key-value pairs of the given key from the pairs
+// printed along with all messages sent with the package-level functions.
+func RemoveData(key string) {
+	defaultLogger.RemoveData(key)
+}
+
+// ReplaceData sets the value of the key-value pair of the given key printed
+// along with all messages sent with this Logger, replacing the previous values
+// of the key instead of adding a pair like AddData. The pair is added if the
+// key is not set yet. The Loggers created from this Logger are not affected.
+func (l *Logger) ReplaceData(key string, value interface{}) {
+	key, err := checkKey(key)
+	if err != nil {
+		panic(err)
+	}
+	value = filterDataValue(key, value)
+
+	mu.Lock()
+	d := make(Data, 0, len(l.data)+1)
+	replaced := false
+	for _, kv := range l.data {
+		if kv.Key == key {
+			if replaced {
+				continue
+			}
+			kv.Value = value
+			replaced = true
+		}
+		d = append(d, kv)
 	}
+	if !replaced {
+		d = append(d, KVPair{Key: key, Value: value})
+	}
+	l.data = d
+	mu.Unlock()
+}
+
+// ReplaceData sets the value of the key-value pair of the given key printed
+// along with all messages sent with the package-level functions, replacing the
+// previous values of the key.
+func ReplaceData(key string, value interface{}) {
+	defaultLogger.ReplaceData(key, value)
+}
 
-	for i := 0; i < len(data)/2; i++ {
-		key, ok := data[2*i].(string)
+// With returns a new Logger that prints the given key-value pairs along with
+// all its messages. Unlike SetData and AddData, it leaves this Logger
+// unchanged so it is safe to use on a Logger shared between goroutines:
+//
+//	log := say.With("user_id", id, "path", r.URL.Path)
+func (l *Logger) With(data ...interface{}) *Logger {
+	log := l.NewLogger()
+	if err := log.data.appendData(data); err != nil {
+		panic(err)
+	}
+	return log
+}
+
+// With returns a new Logger that prints the given key-value pairs along with
+// all its messages in addition to the package-level data.
+func With(data ...interface{}) *Logger {
+	return defaultLogger.With(data...)
+}
+
+// A DataGroup is a group of key-value pairs created with Group.
+type DataGroup struct {
+	name string
+	data Data
+	err  error
+}
+
+// Group groups key-value pairs under a name. It is passed in place of a
+// key-value pair to the functions accepting data:
+//
+//	say.Info("Request", say.Group("http", "method", "GET", "status", 200))
+//	// Output:
+//	INFO  Request	| http.method="GET" http.status=200
+//
+// With the JSONData option, the group is a nested object:
+//
+//	INFO  Request	| {"http":{"method":"GET","status":200}}
+//
+// In the Data of a Message, a group is a single pair whose value is the Data
+// of the group.
+func Group(name string, data ...interface{}) DataGroup {
+	name, err := checkKey(name)
+	if err != nil {
+		return DataGroup{err: err}
+	}
+	g := DataGroup{name: name}
+	g.err = g.data.appendData(data)
+	return g
+}
+
+func (d *Data) appendData(data []interface{}) error {
+	for i := 0; i < len(data); i += 2 {
+		if g, ok := data[i].(DataGroup); ok {
+			if g.err != nil {
+				return g.err
+			}
+			*d = append(*d, KVPair{Key: g.name, Value: g.data})
+			i--
+			continue
+		}
+		if b, ok := data[i].(*DataBuilder); ok {
+			if b.err != nil {
+				return b.err
+			}
+			*d = append(*d, b.data...)
+			i--
+			continue
+		}
+		if r, ok := data[i].(SampleRate); ok {
+			if r < 1 {
+				*d = append(*d, KVPair{Key: "sample_rate", Value: float64(r)})
+			}
+			i--
+			continue
+		}
+		if i+1 == len(data) {
+			return errOddNumArgs
+		}
+
+		key, ok := data[i].(string)
 		if !ok {
 			return errKeyNotString
 		}
-		if err := isKeyValid(key); err != nil {
+		key, err := checkKey(key)
+		if err != nil {
 			return err
 		}
 		*d = append(*d, KVPair{
 			Key:   key,
-			Value: filterDataValue(data[2*i+1]),
+			Value: filterDataValue(key, data[i+1]),
 		})
 	}
 	return nil
 }
 
-func filterDataValue(v interface{}) interface{} {
+func filterDataValue(key string, v interface{}) interface{} {
+	if enc := encoderOf(v); enc != nil {
+		buf := getBuffer()
+		buf.appendEncoded(enc, v)
+		return buf.String()
+	}
+
 	switch t := v.(type) {
 	case string:
 		return t
 	case error:
 		return t.Error()
+	case time.Time:
+		return t
+	case time.Duration:
+		return t
+	case []byte:
+		return encodeBytes(t)
+	case []string:
+		// Copy the slices and maps since they may be modified once sent.
+		return append([]string(nil), t...)
+	case []int:
+		return append([]int(nil), t...)
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, v := range t {
+			if v != nil {
+				v = filterDataValue(key+"."+k, v)
+			}
+			m[k] = v
+		}
+		return m
+	case net.IP, netip.Addr, *url.URL, url.URL, [16]byte:
+		buf := getBuffer()
+		buf.appendBuiltin(t)
+		return buf.String()
 	case fmt.Stringer:
 		return t.String()
 	case func() string:
-		return t()
+		return callStringHook(key, t)
 	case Hook:
 		return t
+	case Data:
+		return t
 	case int:
 		return t
 	case uint:
@@ -128,3 +317,47 @@ func (d Data) Get(key string) (value interface{}, ok bool) {
 	}
 	return value, ok
 }
+
+// GetTime gets the time associated with the given key. If the given key does
+// not exist or its value is not a time, ok is false.
+func (d Data) GetTime(key string) (t time.Time, ok bool) {
+	v, ok := d.Get(key)
+	if !ok {
+		return time.Time{}, false
+	}
+	switch v := v.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		return t, err == nil
+	}
+	return time.Time{}, false
+}
+
+// GetDuration gets the duration associated with the given key. If the given
+// key does not exist or its value is not a duration, ok is false.
+func (d Data) GetDuration(key string) (time.Duration, bool) {
+	v, ok := d.Get(key)
+	if !ok {
+		return 0, false
+	}
+	switch v := v.(type) {
+	case time.Duration:
+		return v, true
+	case string:
+		d, err := time.ParseDuration(v)
+		return d, err == nil
+	}
+	return 0, false
+}
+
+// isOverridden reports whether the key of the i-th pair is set again later.
+func (d Data) isOverridden(i int) bool {
+	for _, kv := range d[i+1:] {
+		if d[i].Key == kv.Key {
+			return true
+		}
+	}
+	return false
+}
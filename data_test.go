@@ -1,8 +1,10 @@
 package say
 
 import (
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 )
 
 func TestSetDataError(t *testing.T) {
@@ -53,15 +55,136 @@ func TestAddDataError(t *testing.T) {
 	}
 }
 
+func TestWith(t *testing.T) {
+	expect(t, func() {
+		parent := new(Logger).With("a", 1)
+		child1 := parent.With("b", 2)
+		child2 := parent.With("c", 3)
+		parent.Info("foo")
+		child1.Info("foo")
+		child2.Info("foo", "d", 4)
+	}, []string{
+		"INFO  foo	| a=1",
+		"INFO  foo	| a=1 b=2",
+		"INFO  foo	| a=1 c=3 d=4",
+	})
+
+	defer func() {
+		if err := recover(); err != errOddNumArgs {
+			t.Errorf("With(\"a\") = %v, want %v", err, errOddNumArgs)
+		}
+	}()
+	With("a")
+}
+
+func TestLoggerData(t *testing.T) {
+	log := NewLogger()
+	if d := log.Data(); len(d) != 0 {
+		t.Errorf("Logger.Data() = %s, want ()", toString(d))
+	}
+	log.SetData("a", 1)
+	child := log.With("b", "c")
+	d := child.Data()
+	want := `(K:"a", V:'\x01'),(K:"b", V:"c")`
+	if got := toString(d); got != want {
+		t.Errorf("Logger.Data() = %s, want %s", got, want)
+	}
+	d[0].Value = 2
+	if got := toString(child.Data()); got != want {
+		t.Errorf("Logger.Data() = %s after modifying a copy, want %s", got, want)
+	}
+}
+
+func TestRemoveData(t *testing.T) {
+	expect(t, func() {
+		log := NewLogger()
+		log.SetData("a", 1, "request_id", 2, "b", 3)
+		child := log.With("c", 4)
+		log.ReplaceData("request_id", 5)
+		log.Info("foo")
+		log.ReplaceData("d", 6)
+		log.RemoveData("a")
+		log.Info("foo")
+		child.Info("foo")
+	}, []string{
+		"INFO  foo	| a=1 request_id=5 b=3",
+		"INFO  foo	| request_id=5 b=3 d=6",
+		"INFO  foo	| a=1 request_id=2 b=3 c=4",
+	})
+
+	defer func() {
+		if err := recover(); err != errKeyEmpty {
+			t.Errorf("ReplaceData(\"\") = %v, want %v", err, errKeyEmpty)
+		}
+	}()
+	ReplaceData("", 1)
+}
+
 func TestDataFormat(t *testing.T) {
 	expect(t, func() {
 		Value("foo", float32(-.61))
 		Value("foo", true)
 		Value("foo", []int{1, 2, 3})
+		Value("foo", 100000000)
+		Value("foo", uint64(1<<63+1))
 	}, []string{
 		"VALUE foo:-0.61",
 		"VALUE foo:true",
 		"VALUE foo:[1 2 3]",
+		"VALUE foo:100000000",
+		"VALUE foo:9223372036854775809",
+	})
+}
+
+func TestTimeData(t *testing.T) {
+	date := time.Date(2015, 9, 1, 21, 37, 0, 5e6, time.UTC)
+	expect(t, func() {
+		Info("foo", "t", date, "d", 12300*time.Microsecond)
+		NewLogger(JSONData(true)).Info("foo", "t", date, "d", time.Minute)
+	}, []string{
+		`INFO  foo	| t=2015-09-01T21:37:00.005Z d=12.3ms`,
+		`INFO  foo	| {"t":"2015-09-01T21:37:00.005Z","d":"1m0s"}`,
+	})
+}
+
+func TestStructuredData(t *testing.T) {
+	tags := []string{"a", `b"c`}
+	m := map[string]interface{}{
+		"z":    1,
+		"tags": []string{"x"},
+		"nil":  nil,
+		"err":  errors.New("oops"),
+		"sub":  map[string]interface{}{"b": true},
+	}
+	expect(t, func() {
+		log := NewLogger()
+		log.SetData("tags", tags)
+		tags[0] = "modified"
+		log.Info("foo", "ids", []int{1, 2}, "empty", []int{}, "m", m)
+		NewLogger(JSONData(true)).Info("foo", "tags", []string{"a"}, "m", map[string]interface{}{"a": 1})
+	}, []string{
+		`INFO  foo	| tags=["a","b\"c"] ids=[1,2] empty=[] ` +
+			`m={"err":"oops","nil":null,"sub":{"b":true},"tags":["x"],"z":1}`,
+		`INFO  foo	| {"tags":["a"],"m":{"a":1}}`,
+	})
+}
+
+func TestGroup(t *testing.T) {
+	expect(t, func() {
+		http := Group("http", "method", "GET", "status", 200)
+		Info("foo", http, "a", 1)
+		Info("foo", "a", 1, Group("outer", Group("inner", "b", 2)))
+		NewLogger(JSONData(true)).Info("foo", http, "a", 1)
+		Info("foo", Group("", "a", 1))
+		Info("foo", Group("g", "a"))
+	}, []string{
+		`INFO  foo	| http.method="GET" http.status=200 a=1`,
+		`INFO  foo	| a=1 outer.inner.b=2`,
+		`INFO  foo	| {"http":{"method":"GET","status":200},"a":1}`,
+		`ERROR say: key is empty`,
+		`INFO  foo`,
+		`ERROR say: odd number of data arguments`,
+		`INFO  foo`,
 	})
 }
 
@@ -124,7 +247,37 @@ func TestDataGet(t *testing.T) {
 		got, ok := d.Get(tt.key)
 		if got != tt.want || ok != tt.ok {
 			t.Errorf("Data.Get(%q) = (%v, %v), want (%v, %v)",
-				got, ok, tt.want, tt.ok)
+				tt.key, got, ok, tt.want, tt.ok)
 		}
 	}
 }
+
+func TestDataGetTime(t *testing.T) {
+	date := time.Date(2015, 9, 1, 21, 37, 0, 0, time.UTC)
+	d := Data{
+		{"time", date},
+		{"time_string", "2015-09-01T21:37:00Z"},
+		{"duration", time.Second},
+		{"duration_string", "1.5s"},
+		{"int", 5},
+	}
+
+	if got, ok := d.GetTime("time"); !ok || !got.Equal(date) {
+		t.Errorf("Data.GetTime(\"time\") = (%v, %v), want (%v, true)", got, ok, date)
+	}
+	if got, ok := d.GetTime("time_string"); !ok || !got.Equal(date) {
+		t.Errorf("Data.GetTime(\"time_string\") = (%v, %v), want (%v, true)", got, ok, date)
+	}
+	if _, ok := d.GetTime("int"); ok {
+		t.Error("Data.GetTime(\"int\") is ok")
+	}
+	if got, ok := d.GetDuration("duration"); !ok || got != time.Second {
+		t.Errorf("Data.GetDuration(\"duration\") = (%v, %v), want (1s, true)", got, ok)
+	}
+	if got, ok := d.GetDuration("duration_string"); !ok || got != 1500*time.Millisecond {
+		t.Errorf("Data.GetDuration(\"duration_string\") = (%v, %v), want (1.5s, true)", got, ok)
+	}
+	if _, ok := d.GetDuration("foo"); ok {
+		t.Error("Data.GetDuration(\"foo\") is ok")
+	}
+}
@@ -0,0 +1,8 @@
+//go:build saydiscard_debug
+// +build saydiscard_debug
+
+package say
+
+// discardDebug is true when building with the saydiscard_debug tag. Debug
+// calls are then compiled to no-ops.
+const discardDebug = true
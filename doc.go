@@ -9,7 +9,7 @@ Introduction
 By default, Say prints all messages (logs and metrics) to standard output.
 
 When a listener is set using SetListener(), messages are handler by the listener
-in a goroutine.
+in a goroutine. TeeOutput(true) keeps printing them to the output as well.
 
 
 Logging functions
@@ -21,10 +21,18 @@ Say provides 5 severity levels:
  - Error
  - Fatal
 
+Debug messages are only printed in debug mode (see SetDebug). Building with the
+saydiscard_debug tag turns the debug mode off for good so that Debug calls
+guarded by DebugEnabled are removed by the compiler.
+
+FatalExit prints a FATAL message and exits the program with the given status
+once the message queue is flushed. Functions registered with OnExit are run
+right before exiting.
+
 
 Metrics functions
 
-Say provides 4 metrics-reporting functions:
+Say provides 6 metrics-reporting functions:
  - Event: track the occurence of a particular event (user sign-up, query to the
    database)
  - Value: measure a value associated with a particular event (number of items
@@ -33,12 +41,18 @@ Say provides 4 metrics-reporting functions:
    call duration)
  - Gauge: capture the current value of something that changes over time (number
    of active goroutines, number of connected users)
+ - GaugeAdd and GaugeSub: change the value of a gauge by a delta (a connection
+   opened or closed)
+ - Histogram: measure a value whose distribution matters (size of requests)
+ - Unique: count the distinct IDs seen (daily active users)
 
 These metrics are directly inspired from StatsD metrics:
  - Event: counter
  - Value: histogram / timing
  - Timing.Say: timing
  - Gauge: gauge
+ - Histogram: histogram / distribution
+ - Unique: set
 
 See the function's descriptions below for more info.
 
@@ -47,6 +61,30 @@ Links:
  - Datadog documentation: http://docs.datadoghq.com/guides/metrics/#counters
 
 
+User errors
+
+UserError prints USER messages: errors meant to be shown to end users and
+identified by a code. Their user-facing text is looked up in the catalog set
+with SetCatalog and they can be written to a separate output using
+RedirectUser:
+
+	say.SetCatalog(say.Catalog{"ERR_QUOTA": "Your quota is exceeded."})
+	say.UserError("ERR_QUOTA", "user_id", 42)
+	// Output:
+	USER  ERR_QUOTA:Your quota is exceeded.	| user_id=42
+
+
+Audit trails
+
+Audit prints AUDIT messages recording the actions that must be kept for
+security or compliance reasons. They are never sampled, rate limited nor
+dropped by the tenant quotas:
+
+	say.Audit("account.delete", "by", "admin", "account_id", 42)
+	// Output:
+	AUDIT account.delete	| by="admin" account_id=42
+
+
 Package-level or methods
 
 These functions can be called at a package-level or you can create a Logger and
@@ -71,5 +109,12 @@ All logging and metric-reporting functions also accept key-value pairs:
 	Info("Hello!", "name", "Bob", "age", 30)
 	// Output:
 	INFO  Hello!  | name="Bob" age=30
+
+Keys of metrics and data must not be empty nor contain tabs or newlines. The
+':', '=' and '\' characters are escaped with a backslash in the output:
+
+	Value(`C:\tmp`, 3)
+	// Output:
+	VALUE C\:\\tmp:3
 */
 package say
@@ -0,0 +1,128 @@
+package say
+
+import (
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strconv"
+)
+
+// A Buffer is the buffer in which an Encoder writes the textual form of a
+// value.
+type Buffer struct {
+	b *buffer
+}
+
+// Write appends p to the buffer. It never returns an error.
+func (b *Buffer) Write(p []byte) (int, error) {
+	b.b.appendBytes(p)
+	return len(p), nil
+}
+
+// WriteString appends s to the buffer. It never returns an error.
+func (b *Buffer) WriteString(s string) (int, error) {
+	b.b.appendString(s)
+	return len(s), nil
+}
+
+// WriteByte appends c to the buffer. It never returns an error.
+func (b *Buffer) WriteByte(c byte) error {
+	b.b.appendByte(c)
+	return nil
+}
+
+// AppendInt appends the decimal form of i to the buffer.
+func (b *Buffer) AppendInt(i int64) {
+	b.b.appendInt(i)
+}
+
+// AppendFloat appends the shortest decimal form of f to the buffer.
+func (b *Buffer) AppendFloat(f float64) {
+	b.b.buf = strconv.AppendFloat(b.b.buf, f, 'g', -1, 64)
+}
+
+// An Encoder writes the textual form of v to b.
+type Encoder func(b *Buffer, v interface{})
+
+var encoders = make(map[reflect.Type]Encoder)
+
+// RegisterEncoder registers the encoder of the values of type t, so that
+// they are formatted efficiently instead of with fmt.Sprint or their String
+// method. The textual form written by the encoder is used as is in the
+// content of messages and as a quoted string in data:
+//
+//	say.RegisterEncoder(reflect.TypeOf(uuid.UUID{}), func(b *say.Buffer, v interface{}) {
+//		id := v.(uuid.UUID)
+//		b.Write(hexUUID(id[:]))
+//	})
+//
+// An encoder registered for a type replaces the previous one. A nil encoder
+// removes the encoder of the type.
+//
+// RegisterEncoder must be called before sending any message, e.g. in an init
+// function. It must not be called concurrently with the other functions of
+// this package.
+func RegisterEncoder(t reflect.Type, enc Encoder) {
+	if enc == nil {
+		delete(encoders, t)
+		return
+	}
+	encoders[t] = enc
+}
+
+// encoderOf returns the encoder registered for the type of v, if any.
+func encoderOf(v interface{}) Encoder {
+	if len(encoders) == 0 || v == nil {
+		return nil
+	}
+	return encoders[reflect.TypeOf(v)]
+}
+
+// appendEncoded appends the textual form of v written by enc.
+func (b *buffer) appendEncoded(enc Encoder, v interface{}) {
+	enc(&Buffer{b}, v)
+}
+
+// appendQuoteEncoded appends the quoted textual form of v written by enc. If
+// json is true, it is quoted as a JSON string.
+func (b *buffer) appendQuoteEncoded(enc Encoder, v interface{}, json bool) {
+	tmp := getBuffer()
+	tmp.appendEncoded(enc, v)
+	b.appendQuoteValue(string(tmp.buf), json)
+	putBuffer(tmp)
+}
+
+// appendBuiltin appends the textual form of the values of the types encoded by
+// Say without reflection and reports whether v is one of them. The password of
+// URLs is redacted and 16-byte arrays are formatted as UUIDs.
+func (b *buffer) appendBuiltin(v interface{}) bool {
+	switch t := v.(type) {
+	case net.IP:
+		b.appendString(t.String())
+	case netip.Addr:
+		b.buf = t.AppendTo(b.buf)
+	case *url.URL:
+		b.appendString(t.Redacted())
+	case url.URL:
+		b.appendString(t.Redacted())
+	case [16]byte:
+		b.appendUUID(t)
+	default:
+		return false
+	}
+	return true
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendUUID appends id in the canonical form of UUIDs, e.g.
+// "123e4567-e89b-12d3-a456-426614174000".
+func (b *buffer) appendUUID(id [16]byte) {
+	for i, c := range id {
+		if i == 4 || i == 6 || i == 8 || i == 10 {
+			b.appendByte('-')
+		}
+		b.buf = append(b.buf, hexDigits[c>>4], hexDigits[c&0xf])
+	}
+}
@@ -0,0 +1,99 @@
+package say
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// An ErrorChain tells how the errors wrapping other errors are printed by
+// Error and Fatal. The values can be combined with a bitwise OR.
+type ErrorChain int
+
+// All the available error chain flags.
+const (
+	// ErrorChainContent lists the errors wrapped by the error after its
+	// message, one per line with its type:
+	//
+	//	ERROR read config: open app.conf: no such file or directory
+	//	      caused by: *fs.PathError: open app.conf: no such file or directory
+	//	      caused by: syscall.Errno: no such file or directory
+	ErrorChainContent ErrorChain = 1 << iota
+	// ErrorChainData adds the message of the error and of each error it
+	// wraps as data: error_0 for the error itself, error_1 for the error it
+	// wraps and so on.
+	ErrorChainData
+)
+
+var errorChain ErrorChain
+
+// SetErrorChain sets how the errors wrapping other errors are printed. By
+// default only the message of the error is printed.
+//
+// The chain is built with errors.Unwrap.
+//
+// This function must not be called concurrently with the other functions of
+// this package.
+func SetErrorChain(c ErrorChain) {
+	errorChain = c
+}
+
+// appendErrorChain appends the errors wrapped by err to the content of an
+// error message and returns the data with the chain appended, according to
+// the error chain setting.
+func (b *buffer) appendErrorChain(err error, data []interface{}) []interface{} {
+	if errorChain&ErrorChainData != 0 && errors.Unwrap(err) != nil {
+		// Do not modify the array of the caller.
+		data = data[:len(data):len(data)]
+		for i, e := 0, err; e != nil; i, e = i+1, errors.Unwrap(e) {
+			data = append(data, "error_"+strconv.Itoa(i), e.Error())
+		}
+	}
+	if errorChain&ErrorChainContent != 0 {
+		for e := errors.Unwrap(err); e != nil; e = errors.Unwrap(e) {
+			b.appendString("\ncaused by: ")
+			b.appendString(fmt.Sprintf("%T", e))
+			b.appendString(": ")
+			b.appendString(e.Error())
+		}
+	}
+	return data
+}
+
+// errorCallers returns the program counters of the stack trace captured by err
+// or by the deepest error it wraps having one, if any. The stack trace is
+// captured either by a Callers() []uintptr method or by a StackTrace method
+// returning a slice of program counters, like the errors of
+// github.com/pkg/errors.
+func errorCallers(err error) []uintptr {
+	var pcs []uintptr
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if p := callersOf(e); p != nil {
+			pcs = p
+		}
+	}
+	return pcs
+}
+
+func callersOf(err error) []uintptr {
+	if c, ok := err.(interface{ Callers() []uintptr }); ok {
+		return c.Callers()
+	}
+
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() {
+		return nil
+	}
+	t := m.Type()
+	if t.NumIn() != 0 || t.NumOut() != 1 || t.Out(0).Kind() != reflect.Slice ||
+		t.Out(0).Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+	st := m.Call(nil)[0]
+	pcs := make([]uintptr, st.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(st.Index(i).Uint())
+	}
+	return pcs
+}
@@ -0,0 +1,81 @@
+package say
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestErrorChain(t *testing.T) {
+	defer SetErrorChain(0)
+	base := errors.New("no such file")
+	err := fmt.Errorf("read config: %w", fmt.Errorf("open app.conf: %w", base))
+
+	SetErrorChain(0)
+	expect(t, func() {
+		Error(err)
+	}, []string{
+		`ERROR read config: open app.conf: no such file`,
+	})
+
+	SetErrorChain(ErrorChainContent)
+	expect(t, func() {
+		Error(err)
+		Error(base)
+	}, []string{
+		"ERROR read config: open app.conf: no such file\n" +
+			"      caused by: *fmt.wrapError: open app.conf: no such file\n" +
+			"      caused by: *errors.errorString: no such file",
+		`ERROR no such file`,
+	})
+
+	SetErrorChain(ErrorChainData)
+	expect(t, func() {
+		Error(err, "a", 1)
+		Error(base)
+	}, []string{
+		`ERROR read config: open app.conf: no such file	| a=1 ` +
+			`error_0="read config: open app.conf: no such file" ` +
+			`error_1="open app.conf: no such file" error_2="no such file"`,
+		`ERROR no such file`,
+	})
+}
+
+type frame uintptr
+
+type tracedError struct {
+	msg   string
+	stack []frame
+}
+
+func (e *tracedError) Error() string { return e.msg }
+
+func (e *tracedError) StackTrace() []frame { return e.stack }
+
+func newTracedError(msg string) error {
+	var pcs [32]uintptr
+	n := runtime.Callers(1, pcs[:])
+	err := &tracedError{msg: msg}
+	for _, pc := range pcs[:n] {
+		err.stack = append(err.stack, frame(pc))
+	}
+	return err
+}
+
+func TestErrorOwnStackTrace(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", newTracedError("oops"))
+
+	var st string
+	SetListener(func(m *Message) {
+		st = m.StackTrace()
+	})
+	NewLogger(SkipStackFrames(0)).Error(err)
+	Flush()
+	SetListener(nil)
+
+	if i := strings.IndexByte(st, '\n'); i == -1 || !strings.HasSuffix(st[:i], ".newTracedError(...)") {
+		t.Errorf("stack trace does not start where the error was created:\n%s", st)
+	}
+}
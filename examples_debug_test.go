@@ -0,0 +1,39 @@
+//go:build !saydiscard_debug
+// +build !saydiscard_debug
+
+package say_test
+
+import "gopkg.in/say.v0"
+
+// The examples printing DEBUG messages, which are discarded with the
+// saydiscard_debug tag.
+
+func ExampleLogger_Debug() {
+	log := new(say.Logger)
+	say.SetDebug(false)
+	log.Debug("foo")
+	say.SetDebug(true)
+	log.Debug("bar")
+	// Output:
+	// DEBUG bar
+}
+
+func ExampleDebug() {
+	say.SetDebug(false)
+	say.Debug("foo")
+	say.SetDebug(true)
+	say.Debug("bar")
+	// Output:
+	// DEBUG bar
+}
+
+func ExampleDebugHook() {
+	query := "SELECT * FROM users WHERE id = ?"
+	say.SetDebug(true)
+	say.Event("db.get_user", "query", say.DebugHook(query)) // Print the query.
+	say.SetDebug(false)
+	say.Event("db.get_user", "query", say.DebugHook(query)) // Omit the query.
+	// Output:
+	// EVENT db.get_user	| query="SELECT * FROM users WHERE id = ?"
+	// EVENT db.get_user
+}
@@ -1,12 +1,17 @@
 package say_test
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"regexp"
 	"runtime"
+	"time"
 
 	"gopkg.in/say.v0"
+	"gopkg.in/say.v0/sayclock"
 )
 
 // A trick to allow examples to correctly capture stdout.
@@ -59,6 +64,35 @@ func ExampleLogger_NewLogger() {
 	// INFO  hello	| id=5 age=53
 }
 
+func ExampleJSONData() {
+	log := say.NewLogger(say.JSONData(true))
+	log.Info("hello", "id", 5, "foo", "bar")
+	// Output:
+	// INFO  hello	| {"id":5,"foo":"bar"}
+}
+
+func ExampleForTenant() {
+	say.SetTenantQuota(1000, time.Minute) // Limit each tenant to 1000 msg/min.
+	defer say.SetTenantQuota(0, 0)
+
+	log := say.ForTenant("acme")
+	log.Event("file_uploaded")
+	// Output:
+	// EVENT file_uploaded	| tenant="acme"
+}
+
+func ExampleWithOutput() {
+	f, err := os.Create("access.log")
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+
+	access := say.NewLogger(say.WithOutput(f))
+	access.Info("GET /", "status", 200) // Printed to access.log.
+	say.Info("Serving...")              // Printed to the standard output.
+}
+
 func ExampleLogger_SetData() {
 	log := new(say.Logger)
 	log.SetData("id", 5, "foo", "bar")
@@ -78,20 +112,43 @@ func ExampleLogger_AddData() {
 	// INFO  dear	| id=5 foo="bar"
 }
 
-func ExampleLogger_SkipStackFrames() {
+func ExampleSkipStackFrames() {
 	log := say.NewLogger(say.SkipStackFrames(-1)) // Disable stack traces.
 	log.Error("Oops")
 	// Output:
 	// ERROR Oops
 }
 
-func ExampleLogger_DisableStackTraces() {
+func ExampleDisableStackTraces() {
 	say.DisableStackTraces(true) // Disable stack traces.
 	say.Error("Oops")
 	// Output:
 	// ERROR Oops
 }
 
+func ExampleDisableType() {
+	say.DisableType(say.TypeDebug) // Only keep logs of higher severity.
+	say.DisableType(say.TypeValue) // Do not report values.
+	say.Value("search_items", 117)
+	say.Info("hello")
+	say.EnableType(say.TypeDebug)
+	say.EnableType(say.TypeValue)
+	// Output:
+	// INFO  hello
+}
+
+func ExampleCompactStackTraces() {
+	say.CompactStackTraces(true)
+	say.SetListener(func(m *say.Message) {
+		if st := m.StackTrace(); st != "" {
+			fmt.Println(say.ExpandStackTrace(st)) // Restore the full trace.
+		}
+	})
+	defer say.SetListener(nil)
+
+	say.Error("Oops")
+}
+
 func ExampleLogger_Event() {
 	log := new(say.Logger)
 	log.Event("new_user", "id", 7654)
@@ -139,6 +196,18 @@ func ExampleTiming_Say() {
 	// VALUE duration:17ms
 }
 
+func ExampleSetClock() {
+	clock := sayclock.NewFake(time.Date(2015, 9, 1, 21, 37, 0, 0, time.UTC))
+	say.SetClock(clock)
+	defer say.SetClock(sayclock.Real)
+
+	t := say.NewTiming()
+	clock.Add(250 * time.Millisecond)
+	t.Say("duration", "at", say.TimeHook("15:04:05.000"))
+	// Output:
+	// VALUE duration:17ms	| at="21:37:00.250"
+}
+
 func ExampleTime() {
 	say.Time("duration", func() {
 		// The code that needs to be timed.
@@ -169,23 +238,12 @@ func ExampleGauge() {
 	// GAUGE connected_users:73
 }
 
-func ExampleLogger_Debug() {
-	log := new(say.Logger)
-	say.SetDebug(false)
-	log.Debug("foo")
+func ExampleDebugEnabled() {
 	say.SetDebug(true)
-	log.Debug("bar")
-	// Output:
-	// DEBUG bar
-}
-
-func ExampleDebug() {
+	if say.DebugEnabled() {
+		say.Debug("Goroutines", "n", runtime.NumGoroutine())
+	}
 	say.SetDebug(false)
-	say.Debug("foo")
-	say.SetDebug(true)
-	say.Debug("bar")
-	// Output:
-	// DEBUG bar
 }
 
 func ExampleLogger_Info() {
@@ -229,6 +287,32 @@ func ExampleError() {
 	}
 }
 
+func ExampleNewContext() {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		log := say.NewLogger()
+		log.AddData("request_id", r.Header.Get("X-Request-Id"))
+		ctx := say.NewContext(r.Context(), log)
+
+		getUser(ctx)
+	}
+	http.HandleFunc("/", handler)
+}
+
+func getUser(ctx context.Context) {
+	log := say.FromContext(ctx) // Get the request-scoped Logger.
+	log.Event("db.get_user")
+}
+
+func ExampleErrorContext() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := ctx.Err(); err != nil {
+		// Print the error with the remaining time and the cancellation cause.
+		say.ErrorContext(ctx, err)
+	}
+}
+
 func ExampleLogger_CheckError() {
 	f, err := os.Open("foo.txt")
 	log := new(say.Logger)
@@ -257,6 +341,13 @@ func ExampleFatal() {
 	}
 }
 
+func ExampleUserError() {
+	say.SetCatalog(say.Catalog{"ERR_QUOTA": "Your quota is exceeded."})
+	say.UserError("ERR_QUOTA", "user_id", 42)
+	// Output:
+	// USER  ERR_QUOTA:Your quota is exceeded.	| user_id=42
+}
+
 func ExampleLogger_CapturePanic() {
 	log := new(say.Logger)
 	defer log.CapturePanic()
@@ -285,6 +376,17 @@ func ExampleCaptureStandardLog() {
 	// INFO  Hello from the standard library!
 }
 
+func ExampleCostReport() {
+	say.SetCostAccounting(true)
+	// Run the application...
+	say.SetCostAccounting(false)
+
+	for _, c := range say.CostReport() {
+		fmt.Printf("%s: %d messages, %v, %d bytes\n",
+			c.CallSite, c.Count, c.Duration, c.Bytes)
+	}
+}
+
 func ExampleHook() {
 	goroutinesHook := say.Hook(func() interface{} {
 		return runtime.NumGoroutine
@@ -293,18 +395,20 @@ func ExampleHook() {
 	say.SetData("num_goroutine", goroutinesHook)
 }
 
-func ExampleDebugHook() {
-	query := "SELECT * FROM users WHERE id = ?"
-	say.SetDebug(true)
-	say.Event("db.get_user", "query", say.DebugHook(query)) // Print the query.
-	say.SetDebug(false)
-	say.Event("db.get_user", "query", say.DebugHook(query)) // Omit the query.
-	// Output:
-	// EVENT db.get_user	| query="SELECT * FROM users WHERE id = ?"
-	// EVENT db.get_user
-}
-
 func ExampleTimeHook() {
 	// Print the current timestamp with each message.
 	say.SetData("num_goroutine", say.TimeHook("2006-01-02 15:04:05"))
 }
+
+func ExampleFields() {
+	type Request struct {
+		Method string `say:"method"`
+		UserID int    `say:"user_id,omitempty"`
+		Body   []byte // Not logged.
+	}
+
+	req := Request{Method: "GET", UserID: 42}
+	say.Info("Request", say.Fields(req)...)
+	// Output:
+	// INFO  Request	| method="GET" user_id=42
+}
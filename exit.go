@@ -0,0 +1,49 @@
+package say
+
+import "sync"
+
+var (
+	exitMu    sync.Mutex
+	exitFuncs []func(code int)
+)
+
+// OnExit registers a function that is run when Say exits the program: with
+// FatalExit, Must, MustValue, CapturePanic, HandleSignals or Fatal when
+// FatalExits is on. The functions are run in the order they were registered,
+// after the message queue is flushed and right before exiting with the given
+// status.
+func OnExit(f func(code int)) {
+	exitMu.Lock()
+	exitFuncs = append(exitFuncs, f)
+	exitMu.Unlock()
+}
+
+// FatalExit prints a FATAL message with the stack trace, flushes the message
+// queue, runs the functions registered with OnExit and exits the program with
+// the given status, whether FatalExits is on or not:
+//
+//	say.FatalExit(3, "Cannot read the configuration", "path", path)
+func (l *Logger) FatalExit(code int, v interface{}, data ...interface{}) {
+	l.error(TypeFatal, v, data, 1)
+	exitWith(code)
+}
+
+// FatalExit prints a FATAL message with the stack trace, flushes the message
+// queue, runs the functions registered with OnExit and exits the program with
+// the given status, whether FatalExits is on or not.
+func FatalExit(code int, v interface{}, data ...interface{}) {
+	defaultLogger.FatalExit(code, v, data...)
+}
+
+// exitWith flushes the message queue, runs the functions registered with
+// OnExit and exits with the given status.
+func exitWith(code int) {
+	Flush()
+	exitMu.Lock()
+	funcs := exitFuncs
+	exitMu.Unlock()
+	for _, f := range funcs {
+		f(code)
+	}
+	exit(code)
+}
@@ -0,0 +1,55 @@
+package say
+
+import "testing"
+
+func TestFatalExit(t *testing.T) {
+	var events []string
+	exit = func(code int) {
+		events = append(events, "exit")
+		if code != 3 {
+			t.Errorf("exit code = %d, want 3", code)
+		}
+	}
+	OnExit(func(code int) {
+		events = append(events, "hook")
+		if code != 3 {
+			t.Errorf("OnExit code = %d, want 3", code)
+		}
+	})
+	defer func() {
+		exit = func(int) {}
+		exitFuncs = nil
+	}()
+
+	SetListener(func(m *Message) {
+		events = append(events, string(m.Type))
+	})
+	FatalExit(3, "no config", "path", "/etc/app")
+	SetListener(nil)
+
+	want := []string{"FATAL", "hook", "exit"}
+	if len(events) != len(want) {
+		t.Fatalf("events = %q, want %q", events, want)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("events = %q, want %q", events, want)
+			break
+		}
+	}
+}
+
+func TestFatalExitOutput(t *testing.T) {
+	var code int
+	exit = func(c int) { code = c }
+	defer func() { exit = func(int) {} }()
+
+	expect(t, func() {
+		NewLogger().FatalExit(4, "oops", "a", 1)
+	}, []string{
+		"FATAL oops	| a=1",
+	})
+	if code != 4 {
+		t.Errorf("exit code = %d, want 4", code)
+	}
+}
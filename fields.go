@@ -0,0 +1,84 @@
+package say
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var errFieldsNotStruct = errors.New("say: Fields only accepts structs")
+
+// A field is a struct field having a say tag.
+type field struct {
+	index     int
+	key       string
+	omitEmpty bool
+}
+
+var fieldsCache sync.Map // map[reflect.Type][]field
+
+// Fields returns the key-value pairs of the fields of the struct v, or of the
+// struct v points to, having a say tag. The tag is the key of the pair. The
+// omitempty option skips the field when it has its zero value, and a "-" tag
+// skips it always:
+//
+//	type Request struct {
+//		Method string `say:"method"`
+//		UserID int    `say:"user_id,omitempty"`
+//		Body   []byte // Not logged.
+//	}
+//
+//	say.Info("Request", say.Fields(req)...)
+//
+// A nil pointer has no fields. Fields panics if v is not a struct or a pointer
+// to a struct.
+func Fields(v interface{}) []interface{} {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		panic(errFieldsNotStruct)
+	}
+
+	fields := fieldsOf(rv.Type())
+	data := make([]interface{}, 0, 2*len(fields))
+	for _, f := range fields {
+		fv := rv.Field(f.index)
+		if f.omitEmpty && fv.IsZero() {
+			continue
+		}
+		data = append(data, f.key, fv.Interface())
+	}
+	return data
+}
+
+// fieldsOf returns the fields of t having a say tag.
+func fieldsOf(t reflect.Type) []field {
+	if fields, ok := fieldsCache.Load(t); ok {
+		return fields.([]field)
+	}
+
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup("say")
+		if !ok || tag == "-" || sf.PkgPath != "" {
+			continue
+		}
+		key, opts := tag, ""
+		if j := strings.IndexByte(tag, ','); j != -1 {
+			key, opts = tag[:j], tag[j+1:]
+		}
+		if key == "" {
+			key = sf.Name
+		}
+		fields = append(fields, field{i, key, opts == "omitempty"})
+	}
+	fieldsCache.Store(t, fields)
+	return fields
+}
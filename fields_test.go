@@ -0,0 +1,34 @@
+package say
+
+import "testing"
+
+type request struct {
+	Method  string `say:"method"`
+	UserID  int    `say:"user_id,omitempty"`
+	Path    string `say:",omitempty"`
+	Body    []byte
+	Secret  string `say:"-"`
+	private int    `say:"private"`
+}
+
+func TestFields(t *testing.T) {
+	expect(t, func() {
+		req := request{Method: "GET", UserID: 7, Body: []byte("x"), Secret: "s", private: 1}
+		Info("foo", Fields(req)...)
+		Info("foo", Fields(&request{Method: "POST", Path: "/"})...)
+		Info("foo", Group("req", Fields(req)...))
+		Info("foo", Fields((*request)(nil))...)
+	}, []string{
+		`INFO  foo	| method="GET" user_id=7`,
+		`INFO  foo	| method="POST" Path="/"`,
+		`INFO  foo	| req.method="GET" req.user_id=7`,
+		`INFO  foo`,
+	})
+
+	defer func() {
+		if err := recover(); err != errFieldsNotStruct {
+			t.Errorf("Fields(1) = %v, want %v", err, errFieldsNotStruct)
+		}
+	}()
+	Fields(1)
+}
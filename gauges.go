@@ -0,0 +1,114 @@
+package say
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	gaugesMu sync.Mutex
+	gauges   = make(map[string]*Message)
+	gaugesOn int32 // Accessed atomically.
+)
+
+// RecordGauges sets whether the latest value of every gauge is kept in memory
+// so that SnapshotGauges can send it again. It is off by default so that
+// sending a gauge costs nothing more than sending another metric. Turning it
+// off forgets the recorded values.
+func RecordGauges(b bool) {
+	on := int32(0)
+	if b {
+		on = 1
+	}
+	atomic.StoreInt32(&gaugesOn, on)
+	if !b {
+		gaugesMu.Lock()
+		gauges = make(map[string]*Message)
+		gaugesMu.Unlock()
+	}
+}
+
+// recordGauge keeps a copy of msg as the latest value of its series. A delta
+// is added to the latest value, or to zero if there is none, so that the kept
+// value is always absolute.
+func recordGauge(msg *Message) {
+	if atomic.LoadInt32(&gaugesOn) == 0 {
+		return
+	}
+
+	k := seriesKey(msg)
+	gaugesMu.Lock()
+	last, ok := gauges[k]
+	if !ok {
+		last = new(Message)
+		gauges[k] = last
+	}
+	if delta, ok := msg.GaugeDelta(); ok {
+		value, _ := last.Float64()
+		copyMessage(last, msg)
+		last.Content = ""
+		last.Value = value + delta
+	} else {
+		copyMessage(last, msg)
+	}
+	gaugesMu.Unlock()
+}
+
+// seriesKey returns the key identifying the series of a gauge: its key, its
+// tenant and its data. The values of Hooks are dynamic so they are ignored.
+// The key comes first so that the series are sorted by key.
+func seriesKey(msg *Message) string {
+	buf := getBuffer()
+	buf.appendString(msg.Key)
+	buf.appendByte(0)
+	buf.appendString(msg.Tenant)
+	for _, kv := range msg.Data {
+		if _, ok := kv.Value.(Hook); ok {
+			continue
+		}
+		buf.appendByte(0)
+		buf.appendString(kv.Key)
+		buf.appendByte('=')
+		buf.appendInterface(kv.Value)
+	}
+	return buf.String()
+}
+
+// copyMessage copies src to dst, reusing the Data array of dst.
+func copyMessage(dst, src *Message) {
+	data := append(dst.Data[:0], src.Data...)
+	*dst = *src
+	dst.Data = data
+}
+
+// SnapshotGauges sends again the latest value of every gauge series sent so
+// far, sorted by key. A series is a gauge key along with a tenant and data
+// pairs. Use it when a downstream system needs all the gauges at once, e.g.
+// right after a listener reconnects, instead of waiting for the gauges to be
+// sent again.
+//
+// The gauges are only recorded when RecordGauges is on. Nothing is sent if the
+// GAUGE messages are disabled.
+func SnapshotGauges() {
+	if !defaultLogger.isEnabled(TypeGauge) {
+		return
+	}
+
+	gaugesMu.Lock()
+	keys := make([]string, 0, len(gauges))
+	for k := range gauges {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	msgs := make([]*Message, len(keys))
+	for i, k := range keys {
+		msgs[i] = getMessage()
+		copyMessage(msgs[i], gauges[k])
+	}
+	gaugesMu.Unlock()
+
+	for _, msg := range msgs {
+		deliver(msg, false)
+	}
+}
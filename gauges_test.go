@@ -0,0 +1,63 @@
+package say
+
+import "testing"
+
+func TestSnapshotGauges(t *testing.T) {
+	RecordGauges(true)
+	defer RecordGauges(false)
+
+	expect(t, func() {
+		Gauge("b", 1)
+		Gauge("a", 1, "x", 1)
+		Gauge("b", 2)
+		NewLogger(Name("db")).Gauge("conns", 5)
+		GaugeAdd("b", 3)
+		GaugeSub("c", 1)
+		Event("foo")
+		SnapshotGauges()
+	}, []string{
+		"GAUGE b:1",
+		"GAUGE a:1	| x=1",
+		"GAUGE b:2",
+		`GAUGE db.conns:5	| logger="db"`,
+		"GAUGE b:+3",
+		"GAUGE c:-1",
+		"EVENT foo",
+		"GAUGE a:1	| x=1",
+		"GAUGE b:5",
+		"GAUGE c:-1",
+		`GAUGE db.conns:5	| logger="db"`,
+	})
+
+	expect(t, func() {
+		DisableType(TypeGauge)
+		defer EnableType(TypeGauge)
+		SnapshotGauges()
+		Event("foo")
+	}, []string{
+		"EVENT foo",
+	})
+}
+
+func TestSnapshotGaugeSeries(t *testing.T) {
+	expect(t, func() {
+		Gauge("disk.free", 10, "disk", "sda")
+		SnapshotGauges()
+		RecordGauges(true)
+		defer RecordGauges(false)
+		Gauge("disk.free", 10, "disk", "sda")
+		Gauge("disk.free", 20, "disk", "sdb")
+		Gauge("disk.free", 15, "disk", "sda")
+		NewLogger().ForTenant("acme").Gauge("disk.free", 5, "disk", "sda")
+		SnapshotGauges()
+	}, []string{
+		`GAUGE disk.free:10	| disk="sda"`,
+		`GAUGE disk.free:10	| disk="sda"`,
+		`GAUGE disk.free:20	| disk="sdb"`,
+		`GAUGE disk.free:15	| disk="sda"`,
+		`GAUGE disk.free:5	| tenant="acme" disk="sda"`,
+		`GAUGE disk.free:15	| disk="sda"`,
+		`GAUGE disk.free:20	| disk="sdb"`,
+		`GAUGE disk.free:5	| tenant="acme" disk="sda"`,
+	})
+}
@@ -0,0 +1,80 @@
+package say
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+var recoverHookPanics = true
+
+// RecoverHookPanics sets whether the panics of the Hooks and of the func()
+// string values are recovered. It is on by default: a panic is turned into an
+// ERROR message with the key of the offending data pair, the pair is omitted
+// and the message is sent anyway. When off, the panic propagates to the caller
+// or to the listener goroutine.
+//
+// This function must not be called concurrently with the other functions of
+// this package.
+func RecoverHookPanics(b bool) {
+	recoverHookPanics = b
+}
+
+var (
+	hookPanicsMu  sync.Mutex
+	hookPanics    []*Message
+	numHookPanics int32 // Accessed atomically.
+)
+
+// callHook returns the value of f or nil if f panics.
+func callHook(key string, f func() interface{}) interface{} {
+	defer recoverHook(key)
+	return f()
+}
+
+// callStringHook returns the value of f or an empty string if f panics.
+func callStringHook(key string, f func() string) string {
+	defer recoverHook(key)
+	return f()
+}
+
+// recoverHook recovers the panic of the Hook of the given key, if any, and
+// queues the ERROR message reporting it. The message cannot be sent right away
+// since Hooks are called while messages are built or printed.
+func recoverHook(key string) {
+	if !recoverHookPanics {
+		return
+	}
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	msg := getMessage()
+	msg.Type = TypeError
+	msg.Content = fmt.Sprint("say: hook panicked: ", r)
+	if key != "" {
+		msg.Data = append(msg.Data, KVPair{Key: "key", Value: key})
+	}
+	hookPanicsMu.Lock()
+	hookPanics = append(hookPanics, msg)
+	atomic.AddInt32(&numHookPanics, 1)
+	hookPanicsMu.Unlock()
+}
+
+// reportHookPanics applies f to the messages reporting the recovered panics.
+func reportHookPanics(f func(*Message)) {
+	if atomic.LoadInt32(&numHookPanics) == 0 {
+		return
+	}
+
+	hookPanicsMu.Lock()
+	msgs := hookPanics
+	hookPanics = nil
+	atomic.StoreInt32(&numHookPanics, 0)
+	hookPanicsMu.Unlock()
+
+	for _, msg := range msgs {
+		f(msg)
+	}
+}
@@ -0,0 +1,52 @@
+package say
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHookPanic(t *testing.T) {
+	bad := Hook(func() interface{} { panic("oops") })
+	expect(t, func() {
+		Info("foo", "a", 1, "bad", bad)
+		Info("foo", "s", func() string { panic(errors.New("boom")) })
+		NewLogger(JSONData(true)).Info("foo", Group("g", "bad", bad))
+		Info(func() string { panic("oops") })
+	}, []string{
+		`INFO  foo	| a=1`,
+		`ERROR say: hook panicked: oops	| key="bad"`,
+		`INFO  foo	| s=""`,
+		`ERROR say: hook panicked: boom	| key="s"`,
+		`INFO  foo	| {"g":{}}`,
+		`ERROR say: hook panicked: oops	| key="bad"`,
+		`INFO  `,
+		`ERROR say: hook panicked: oops`,
+	})
+
+	RecoverHookPanics(false)
+	defer RecoverHookPanics(true)
+	defer func() {
+		if r := recover(); r != "oops" {
+			t.Errorf("recover() = %v, want oops", r)
+		}
+	}()
+	Info("foo", "bad", bad)
+}
+
+func TestHookPanicListener(t *testing.T) {
+	var got []string
+	SetListener(func(m *Message) {
+		got = append(got, m.Content)
+		buf := getBuffer()
+		buf.appendData(m.Data)
+		putBuffer(buf)
+	})
+	Info("foo", "bad", Hook(func() interface{} { panic("oops") }))
+	Flush()
+	SetListener(nil)
+
+	want := []string{"foo", "say: hook panicked: oops"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("the listener received %q, want %q", got, want)
+	}
+}
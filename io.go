@@ -5,6 +5,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"time"
 )
 
 var (
@@ -35,8 +36,14 @@ func SetListener(f func(*Message)) {
 					waitFlush <- struct{}{}
 					continue
 				}
+				size := msg.size
 				listener(msg)
 				putMessage(msg)
+				releaseQueue(size)
+				reportHookPanics(func(m *Message) {
+					listener(m)
+					putMessage(m)
+				})
 			}
 		}()
 	// If old is non-nil and new is nil, stop the listening daemon.
@@ -50,49 +57,207 @@ func SetListener(f func(*Message)) {
 	}
 }
 
-// Flush flushes the message queue. It is a no-op when SetListener has not been
-// used.
+var teeOutput bool
+
+// TeeOutput sets whether messages are still printed to the output when a
+// listener is set. It is off by default: SetListener suppresses the output.
+// When on, each message is printed before being sent to the listener, which
+// is useful while migrating to a listener or debugging one.
+//
+// This function must not be called concurrently with the other functions of
+// this package.
+func TeeOutput(b bool) {
+	teeOutput = b
+}
+
+// Flush prints the pending aggregated metrics (see SetAggregation), counts of
+// rate-limited messages (see RateLimit) and error summary (see
+// SetErrorSummary), and flushes the message queue.
 func Flush() {
+	FlushAggregates()
+	flushRateLimits()
+	flushSummary()
 	if listener != nil {
 		ch <- nil
 		<-waitFlush
 	}
 }
 
-func (l *Logger) send(typ Type, content string, data []interface{}) {
+// shouldSend reports whether a message of the given type sent with this Logger
+// must be sent.
+func (l *Logger) shouldSend(typ Type) bool {
+	return l.isEnabled(typ) && l.withinSampling(typ) && l.withinTenantQuota()
+}
+
+// send sends a log message whose content is the textual form of v. The
+// content is only built when the message is actually sent.
+func (l *Logger) send(typ Type, v interface{}, data []interface{}) {
+	if !l.shouldSend(typ) {
+		return
+	}
+	content, ok := v.(string)
+	if !ok {
+		buf := getBuffer()
+		buf.appendValue(v)
+		content = buf.String()
+	}
+	if !l.withinRateLimit(content) {
+		return
+	}
+
 	msg := getMessage()
 	msg.Type = typ
 	msg.Content = content
+	l.sendMessage(msg, data)
+}
+
+// sendValue sends a metric with a numeric value without building its textual
+// form.
+func (l *Logger) sendValue(typ Type, key string, value float64, unit string, data []interface{}) {
+	if !l.shouldSend(typ) || !withinSampleRate(data) || !l.withinRateLimit(key) {
+		return
+	}
+
+	msg := getMessage()
+	msg.Type = typ
+	msg.Key = key
+	msg.Value = value
+	msg.Unit = unit
+	l.sendMessage(msg, data)
+}
+
+// sendText sends a message having a key and a textual value.
+func (l *Logger) sendText(typ Type, key, text string, data []interface{}) {
+	if !l.shouldSend(typ) || !l.withinRateLimit(key) {
+		return
+	}
+
+	msg := getMessage()
+	msg.Type = typ
+	msg.Key = key
+	msg.Content = text
+	l.sendMessage(msg, data)
+}
+
+func (l *Logger) sendMessage(msg *Message, data []interface{}) {
+	var start time.Time
+	accounting := isCostAccountingEnabled()
+	if accounting {
+		start = time.Now()
+	}
+
+	if l.name != "" {
+		if msg.isMetric() {
+			msg.Key = l.name + "." + msg.Key
+		}
+		msg.Data = append(msg.Data, KVPair{Key: "logger", Value: l.name})
+	}
+	if l.caller {
+		msg.Data = append(msg.Data, KVPair{Key: "caller", Value: callSite(l.callerSkip)})
+	}
+	if l.sampler != nil && isSampled(msg.Type) {
+		msg.Data = append(msg.Data, KVPair{Key: "sampled", Value: true})
+	}
+	if l.ttl > 0 {
+		msg.TTL = l.ttl
+		msg.Data = append(msg.Data, KVPair{Key: "ttl_ms", Value: int64(l.ttl / time.Millisecond)})
+	}
 
 	mu.RLock()
 	msg.Data = append(msg.Data, l.data...)
+	msg.jsonData = l.jsonData
+	msg.Tenant = l.tenant
+	msg.out = l.out
 	mu.RUnlock()
 	if len(data) > 0 {
 		if err := msg.Data.appendData(data); err != nil {
-			l.error(TypeError, err, nil, 2)
+			l.error(TypeError, err, nil, 3)
 		}
 	}
+	mu.RLock()
+	r := redactor
+	maxContent, maxValue := maxContentSize, maxValueSize
+	mu.RUnlock()
+	if r != nil {
+		redact(r, msg)
+	}
+	if maxContent > 0 || maxValue > 0 {
+		truncate(msg, maxContent, maxValue)
+	}
+
+	addToSummary(msg)
+	if msg.Type == TypeGauge {
+		recordGauge(msg)
+	}
 
-	if listener == nil {
-		printMessage(msg)
+	var n int
+	if aggregateMessage(msg) {
 		putMessage(msg)
 	} else {
-		ch <- msg
+		n = deliver(msg, accounting)
 	}
+	if accounting {
+		addCost(time.Since(start), n)
+	}
+	reportHookPanics(func(m *Message) { deliver(m, false) })
 }
 
-var out io.Writer = os.Stdout
+// deliver prints msg or sends it to the listener. It returns the number of
+// bytes printed or, if size is true, the size of the message sent to the
+// listener.
+func deliver(msg *Message, size bool) int {
+	if listener == nil || msg.out != nil {
+		n := printMessage(msg)
+		putMessage(msg)
+		return n
+	}
+
+	var n int
+	if teeOutput {
+		n = printMessage(msg)
+	} else if size {
+		n = messageSize(msg)
+	}
+	if !reserveQueue(msg) {
+		putMessage(msg)
+		return n
+	}
+	ch <- msg
+	return n
+}
+
+var (
+	out     io.Writer = os.Stdout
+	userOut io.Writer
+)
+
+// printMessage prints msg to the output and returns the number of bytes
+// printed.
+func printMessage(msg *Message) int {
+	mu.RLock()
+	w := out
+	switch {
+	case msg.out != nil:
+		w = msg.out
+	case msg.Type == TypeUser && userOut != nil:
+		w = userOut
+	}
+	layout, color := printLayout, colored && w == out
+	mu.RUnlock()
 
-func printMessage(msg *Message) {
 	buf := getBuffer()
-	buf.appendString(string(msg.Type))
-	buf.appendByte(' ')
-	buf.appendEscapeString(msg.Content)
-	buf.appendData(msg.Data)
-	buf.appendByte('\n')
+	if layout != "" {
+		buf.appendTimestampLayout(now(), layout)
+		buf.appendByte(' ')
+	}
+	if color {
+		buf.appendColorMessage(msg)
+	} else {
+		buf.appendMessage(msg)
+	}
 
 	mu.RLock()
-	if _, err := out.Write(buf.buf); err != nil {
+	if _, err := w.Write(buf.buf); err != nil {
 		_, err := fmt.Fprintf(os.Stderr, "say: cannot write to output: %v", err)
 		if err != nil {
 			// This isn't our lucky day. Panics since stderr is not writable.
@@ -102,16 +267,46 @@ func printMessage(msg *Message) {
 	}
 	mu.RUnlock()
 
+	n := len(buf.buf)
+	putBuffer(buf)
+	reportHookPanics(func(m *Message) {
+		printMessage(m)
+		putMessage(m)
+	})
+	return n
+}
+
+// messageSize returns the number of bytes msg would take once printed.
+func messageSize(msg *Message) int {
+	buf := getBuffer()
+	buf.appendMessage(msg)
+	n := len(buf.buf)
 	putBuffer(buf)
+	return n
 }
 
 // Redirect redirects the output to the given writer. It returns the writer
 // where outputs were previously redirected to.
 //
-// It is only effective when SetListener has not been used.
+// It is only effective when SetListener has not been used and does not affect
+// the Loggers created with the WithOutput option.
 func Redirect(w io.Writer) (oldW io.Writer) {
 	mu.Lock()
 	oldW, out = out, w
+	updateColored()
+	mu.Unlock()
+	return oldW
+}
+
+// RedirectUser redirects the USER messages to the given writer. It returns the
+// writer where USER messages were previously redirected to.
+//
+// RedirectUser(nil) restores the default behavior which is printing USER
+// messages along with the other messages. It is only effective when
+// SetListener has not been used.
+func RedirectUser(w io.Writer) (oldW io.Writer) {
+	mu.Lock()
+	oldW, userOut = userOut, w
 	mu.Unlock()
 	return oldW
 }
@@ -132,15 +327,13 @@ func CapturePanic() {
 }
 
 func (l *Logger) capturePanic(err interface{}) {
-	if err != nil {
-		l.error(TypeFatal, err, nil, 2)
+	if err == nil {
+		Flush()
+		return
 	}
 
-	Flush()
-
-	if err != nil {
-		exit(2)
-	}
+	l.error(TypeFatal, err, nil, 2)
+	exitWith(2)
 }
 
 // Stubbed out for testing.
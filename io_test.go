@@ -1,6 +1,7 @@
 package say
 
 import (
+	"bytes"
 	"reflect"
 	"testing"
 )
@@ -95,3 +96,52 @@ func TestPanicSetListener(t *testing.T) {
 	defer CapturePanic()
 	panic(content)
 }
+
+func TestWithOutput(t *testing.T) {
+	buf := new(bytes.Buffer)
+	expect(t, func() {
+		log := NewLogger(WithOutput(buf))
+		log.Info("foo")
+		log.NewLogger().Event("bar")
+		Info("baz")
+	}, []string{
+		"INFO  baz",
+	})
+	if got, want := buf.String(), "INFO  foo\nEVENT bar\n"; got != want {
+		t.Errorf("invalid Logger output, got %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	received := 0
+	SetListener(func(*Message) { received++ })
+	NewLogger(WithOutput(buf)).Info("foo")
+	Info("bar")
+	Flush()
+	SetListener(nil)
+	if got, want := buf.String(), "INFO  foo\n"; got != want {
+		t.Errorf("invalid Logger output with a listener, got %q, want %q",
+			got, want)
+	}
+	if received != 1 {
+		t.Errorf("listener received %d messages, want 1", received)
+	}
+}
+
+func TestTeeOutput(t *testing.T) {
+	var received []string
+	expect(t, func() {
+		TeeOutput(true)
+		defer TeeOutput(false)
+		SetListener(func(msg *Message) { received = append(received, msg.Content) })
+		defer SetListener(nil)
+		Info("foo", "a", 1)
+		Event("bar")
+		Flush()
+	}, []string{
+		"INFO  foo	| a=1",
+		"EVENT bar",
+	})
+	if len(received) != 2 || received[0] != "foo" {
+		t.Errorf("listener received %q, want [\"foo\" \"\"]", received)
+	}
+}
@@ -0,0 +1,50 @@
+package say
+
+import (
+	"context"
+	"runtime/trace"
+)
+
+// Job runs a scheduled job and reports its execution, so that missed or
+// failing jobs can be detected:
+//
+//	EVENT nightly_cleanup.start
+//	VALUE nightly_cleanup.duration:1520ms
+//	EVENT nightly_cleanup.success
+//	GAUGE nightly_cleanup.last_success:1441143420
+//
+// The last_success gauge is the Unix time at which the job ended. If f returns
+// an error, it is printed as an ERROR and an EVENT name.failure is sent
+// instead of the success event and gauge. Job returns the error of f.
+func (l *Logger) Job(name string, f func() error) error {
+	name, err := checkKey(name)
+	if err != nil {
+		l.sendError(err, 1)
+		return f()
+	}
+
+	l.sendValue(TypeEvent, name+".start", 1, "", nil)
+	t := l.NewTiming()
+	if traceRegions && trace.IsEnabled() {
+		_, task := trace.NewTask(context.Background(), name)
+		err = f()
+		task.End()
+	} else {
+		err = f()
+	}
+	t.Say(name + ".duration")
+	if err != nil {
+		l.error(TypeError, err, []interface{}{"job", name}, 1)
+		l.sendValue(TypeEvent, name+".failure", 1, "", nil)
+		return err
+	}
+	l.sendValue(TypeEvent, name+".success", 1, "", nil)
+	l.sendValue(TypeGauge, name+".last_success", float64(now().Unix()), "", nil)
+	return nil
+}
+
+// Job runs a scheduled job and reports its execution, so that missed or
+// failing jobs can be detected. See Logger.Job.
+func Job(name string, f func() error) error {
+	return defaultLogger.Job(name, f)
+}
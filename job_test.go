@@ -0,0 +1,41 @@
+package say
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gopkg.in/say.v0/sayclock"
+)
+
+func TestJob(t *testing.T) {
+	c := sayclock.NewFake(time.Date(2015, 9, 1, 21, 37, 0, 0, time.UTC))
+	SetClock(c)
+	defer SetClock(sayclock.Real)
+
+	errOops := errors.New("oops")
+	expect(t, func() {
+		err := Job("cleanup", func() error {
+			c.Add(1520 * time.Millisecond)
+			return nil
+		})
+		if err != nil {
+			t.Errorf("Job() = %v, want nil", err)
+		}
+		err = Job("cleanup", func() error {
+			return errOops
+		})
+		if err != errOops {
+			t.Errorf("Job() = %v, want %v", err, errOops)
+		}
+	}, []string{
+		`EVENT cleanup.start`,
+		`VALUE cleanup.duration:1520ms`,
+		`EVENT cleanup.success`,
+		`GAUGE cleanup.last_success:1441143421`,
+		`EVENT cleanup.start`,
+		`VALUE cleanup.duration:0ms`,
+		`ERROR oops	| job="cleanup"`,
+		`EVENT cleanup.failure`,
+	})
+}
@@ -0,0 +1,194 @@
+package say
+
+import (
+	"bytes"
+	_ "embed" // For JSONSchema.
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// JSONSchema is the JSON Schema of the messages written by
+// Message.WriteJSONTo. The data pairs are written as additional properties;
+// the pairs whose key is one of the properties of the schema are skipped.
+//
+//go:embed message.schema.json
+var JSONSchema string
+
+var (
+	errJSONNotObject = errors.New("say: JSON message is not an object")
+	errJSONType      = errors.New("say: JSON message has no valid type")
+)
+
+// UnmarshalJSON decodes a message written by WriteJSONTo. The timestamp must be
+// in the RFC 3339 format but is not kept. Integer data values are decoded as
+// int, other numbers as float64, arrays as []interface{} and objects as groups,
+// whose value is a Data.
+func (m *Message) UnmarshalJSON(b []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if tok, err := dec.Token(); err != nil {
+		return err
+	} else if tok != json.Delim('{') {
+		return errJSONNotObject
+	}
+
+	*m = Message{Data: m.Data[:0]}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key := tok.(string)
+
+		var s string
+		switch key {
+		case "timestamp", "type", "key", "unit", "content", "stack_trace":
+			err = dec.Decode(&s)
+		case "value":
+			var v *float64
+			err = dec.Decode(&v)
+			if v != nil {
+				m.Value = *v
+			} else {
+				m.Value = math.NaN()
+			}
+		default:
+			var v interface{}
+			v, err = decodeJSONValue(dec)
+			m.Data = append(m.Data, KVPair{Key: key, Value: v})
+		}
+		if err != nil {
+			return err
+		}
+
+		switch key {
+		case "timestamp":
+			if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+				return err
+			}
+		case "type":
+			if len(s) > 5 {
+				return errJSONType
+			}
+			m.Type = Type(s + strings.Repeat(" ", 5-len(s)))
+			if typeBit(m.Type) == 0 {
+				return errJSONType
+			}
+		case "key":
+			m.Key = s
+		case "unit":
+			m.Unit = s
+		case "content":
+			m.Content = s
+		case "stack_trace":
+			m.stack = s
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	if m.Type == "" {
+		return errJSONType
+	}
+	return nil
+}
+
+// decodeJSONValue decodes the next JSON value of dec as a data value.
+func decodeJSONValue(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Number:
+		if i, err := t.Int64(); err == nil && i == int64(int(i)) {
+			return int(i), nil
+		}
+		return t.Float64()
+	case json.Delim:
+		var v interface{}
+		if t == '[' {
+			a := []interface{}{}
+			for dec.More() {
+				e, err := decodeJSONValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				a = append(a, e)
+			}
+			v = a
+		} else {
+			var d Data
+			for dec.More() {
+				k, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				e, err := decodeJSONValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				d = append(d, KVPair{Key: k.(string), Value: e})
+			}
+			v = d
+		}
+		_, err := dec.Token() // The closing delimiter.
+		return v, err
+	}
+	return tok, nil
+}
+
+// appendQuoteValue appends s quoted as a JSON string if json is true or as a
+// Go string otherwise.
+func (b *buffer) appendQuoteValue(s string, json bool) {
+	if json {
+		b.appendJSONString(s)
+	} else {
+		b.appendQuoteString(s)
+	}
+}
+
+// appendJSONString appends s quoted as a JSON string. Control characters are
+// escaped and invalid UTF-8 bytes are replaced by U+FFFD.
+func (b *buffer) appendJSONString(s string) {
+	b.appendByte(quote)
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == quote || c == '\\':
+				b.buf = append(b.buf, '\\', c)
+			case c == '\n':
+				b.buf = append(b.buf, `\n`...)
+			case c == '\r':
+				b.buf = append(b.buf, `\r`...)
+			case c == '\t':
+				b.buf = append(b.buf, `\t`...)
+			case c < ' ' || c == 0x7f:
+				b.buf = append(b.buf, `\u00`...)
+				b.buf = append(b.buf, lowerhex[c>>4], lowerhex[c&0xF])
+			default:
+				b.buf = append(b.buf, c)
+			}
+			i++
+			continue
+		}
+		r, width := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && width == 1 {
+			b.buf = append(b.buf, `�`...)
+		} else {
+			b.buf = append(b.buf, s[i:i+width]...)
+		}
+		i += width
+	}
+	b.appendByte(quote)
+}
+
+func isFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
@@ -0,0 +1,156 @@
+package say
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// validate checks v against the subset of JSON Schema used by JSONSchema:
+// type, enum, required, properties and additionalProperties.
+func validate(t *testing.T, schema map[string]interface{}, v interface{}, path string) {
+	if types, ok := schema["type"]; ok {
+		if _, ok := types.([]interface{}); !ok {
+			types = []interface{}{types}
+		}
+		valid := false
+		for _, typ := range types.([]interface{}) {
+			valid = valid || jsonType(v) == typ
+		}
+		if !valid {
+			t.Errorf("%s: type %s, want %v", path, jsonType(v), types)
+		}
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		valid := false
+		for _, e := range enum {
+			valid = valid || e == v
+		}
+		if !valid {
+			t.Errorf("%s: %v is not one of %v", path, v, enum)
+		}
+	}
+
+	obj, ok := v.(map[string]interface{})
+	props, hasProps := schema["properties"].(map[string]interface{})
+	if !ok || !hasProps {
+		return
+	}
+	for _, key := range schema["required"].([]interface{}) {
+		if _, ok := obj[key.(string)]; !ok {
+			t.Errorf("%s: missing required property %s", path, key)
+		}
+	}
+	for key, value := range obj {
+		if s, ok := props[key]; ok {
+			validate(t, s.(map[string]interface{}), value, path+"."+key)
+		} else {
+			validate(t, schema["additionalProperties"].(map[string]interface{}), value, path+"."+key)
+		}
+	}
+}
+
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(JSONSchema), &schema); err != nil {
+		t.Fatalf("invalid JSONSchema: %v", err)
+	}
+
+	DisableStackTraces(false)
+	defer DisableStackTraces(true)
+	log := NewLogger(JSONData(true))
+	tests := []test{
+		{func() { log.Event("foo") }, nil},
+		{func() { log.Value("foo", math.Inf(1), "nan", math.NaN()) }, nil},
+		{func() { log.Gauge("foo", "bar") }, nil},
+		{func() { log.Histogram("foo", 1.5) }, nil},
+		{func() { log.NewTiming().Say("foo") }, nil},
+		{func() { log.Info("a\x00\x7f\xff\a\v\U0001F600\"\\", "k\x01", "\x02\xfe") }, nil},
+		{func() { log.Warning("foo", "t", time.Second, Group("g", "a", []string{"\x03"})) }, nil},
+		{func() { log.Error("foo", "type", "skipped", "m", map[string]interface{}{"\x04": 1}) }, nil},
+		{func() { log.UserError("ERR") }, nil},
+		{func() { log.Audit("foo", "b", true) }, nil},
+		{func() { log.Unique("foo", 42) }, nil},
+	}
+
+	buf := new(bytes.Buffer)
+	testMessage(t, tests, func(m *Message, _ interface{}) {
+		buf.Reset()
+		m.WriteJSONTo(buf)
+		var v interface{}
+		if err := json.Unmarshal(buf.Bytes(), &v); err != nil {
+			t.Errorf("invalid JSON %s: %v", buf.Bytes(), err)
+			return
+		}
+		validate(t, schema, v, string(m.Type))
+	})
+}
+
+func TestMessageUnmarshalJSON(t *testing.T) {
+	DisableStackTraces(false)
+	defer DisableStackTraces(true)
+	tests := []test{
+		{func() { Event("foo") }, nil},
+		{func() { Value("foo", 1.5, "a", 1, "b", "c") }, nil},
+		{func() { NewTiming().Say("foo") }, nil},
+		{func() { Gauge("foo", "bar") }, nil},
+		{func() { Info("foo\n", Group("g", "a", true, "b", []int{1})) }, nil},
+		{func() { Error("foo", "f", 2.5) }, nil},
+	}
+
+	buf := new(bytes.Buffer)
+	testMessage(t, tests, func(m *Message, _ interface{}) {
+		buf.Reset()
+		m.WriteJSONTo(buf)
+		var got Message
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Errorf("Message.UnmarshalJSON(%s) = %v", buf.Bytes(), err)
+			return
+		}
+		if got.Type != m.Type || got.Key != m.Key || got.Value != m.Value ||
+			got.Unit != m.Unit || got.Content != m.Content ||
+			got.StackTrace() != m.StackTrace() {
+			t.Errorf("Message.UnmarshalJSON(%s) = %+v", buf.Bytes(), got)
+		}
+		want := m.Data
+		if len(want) > 0 {
+			want = Data{{"a", 1}, {"b", "c"}}
+			if m.Type == TypeInfo {
+				want = Data{{"g", Data{{"a", true}, {"b", []interface{}{1}}}}}
+			} else if m.Type == TypeError {
+				want = Data{{"f", 2.5}}
+			}
+		}
+		if !reflect.DeepEqual(got.Data, want) && len(got.Data)+len(want) > 0 {
+			t.Errorf("Message.UnmarshalJSON(%s): Data = %#v, want %#v", buf.Bytes(), got.Data, want)
+		}
+	})
+
+	for _, s := range []string{`[]`, `{"type":"FOO"}`, `{"type":"TOOLONG"}`, `{}`, `{"type":1}`,
+		`{"timestamp":"2015-11-25 15:47:00","type":"INFO"}`} {
+		var m Message
+		if err := json.Unmarshal([]byte(s), &m); err == nil {
+			t.Errorf("Message.UnmarshalJSON(%s) succeeded", s)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package say
+
+import (
+	"errors"
+	"unicode/utf8"
+)
+
+// A KeyPolicy tells how keys containing non-ASCII characters are handled.
+type KeyPolicy int
+
+// All the available key policies.
+const (
+	// AllowKeys keeps non-ASCII keys unchanged. It is the default policy.
+	AllowKeys KeyPolicy = iota
+	// TransliterateKeys replaces the Latin letters with diacritics by their
+	// ASCII counterpart (e.g. "é" by "e") and the other non-ASCII characters
+	// by underscores.
+	TransliterateKeys
+	// RejectKeys rejects non-ASCII keys as invalid.
+	RejectKeys
+)
+
+var (
+	keyPolicy        KeyPolicy
+	keyNormalization bool
+	errKeyNotASCII   = errors.New("say: keys must only contain ASCII characters")
+)
+
+// SetKeyPolicy sets how keys containing non-ASCII characters are handled.
+//
+// This function must not be called concurrently with the other functions of
+// this package.
+func SetKeyPolicy(p KeyPolicy) {
+	keyPolicy = p
+}
+
+// SetKeyNormalization sets whether keys are normalized so that keys that look
+// identical are identical: a Latin letter followed by a combining mark (e.g.
+// "e" followed by U+0301) is replaced by the equivalent precomposed letter
+// (U+00E9) as in the Unicode normalization form C. It is off by default.
+//
+// Only the letters of the Latin-1 Supplement and Latin Extended-A blocks are
+// composed.
+//
+// This function must not be called concurrently with the other functions of
+// this package.
+func SetKeyNormalization(b bool) {
+	keyNormalization = b
+}
+
+// checkKey validates key and returns it normalized according to the key
+// settings.
+func checkKey(key string) (string, error) {
+	if key == "" {
+		return "", errKeyEmpty
+	}
+
+	ascii := true
+	for i := 0; i < len(key); i++ {
+		switch c := key[i]; {
+		case c == '\t' || c == '\n':
+			return "", errKeyInvalid
+		case c >= utf8.RuneSelf:
+			ascii = false
+		}
+	}
+	if ascii {
+		return key, nil
+	}
+
+	if keyNormalization {
+		key = composeLatin(key)
+	}
+	switch keyPolicy {
+	case TransliterateKeys:
+		key = transliterate(key)
+	case RejectKeys:
+		return "", errKeyNotASCII
+	}
+	return key, nil
+}
+
+// isCombiningMark reports whether r is in the Combining Diacritical Marks
+// block.
+func isCombiningMark(r rune) bool {
+	return r >= 0x0300 && r <= 0x036F
+}
+
+// composeLatin replaces the ASCII letters followed by a combining mark by the
+// equivalent precomposed Latin letters.
+func composeLatin(s string) string {
+	runes := make([]rune, 0, len(s))
+	for _, r := range s {
+		if n := len(runes); n > 0 && isCombiningMark(r) {
+			if c, ok := latinCompositions[[2]rune{runes[n-1], r}]; ok {
+				runes[n-1] = c
+				continue
+			}
+		}
+		runes = append(runes, r)
+	}
+	return string(runes)
+}
+
+// transliterate converts s to ASCII.
+func transliterate(s string) string {
+	buf := getBuffer()
+	for _, r := range s {
+		switch {
+		case r < utf8.RuneSelf:
+			buf.appendByte(byte(r))
+		case isCombiningMark(r):
+			// Drop the diacritics of decomposed letters.
+		case r >= 0xC0 && r < 0xC0+rune(len(latinASCII)):
+			buf.appendString(latinASCII[r-0xC0])
+		default:
+			buf.appendByte('_')
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,55 @@
+package say
+
+import "testing"
+
+func TestCheckKey(t *testing.T) {
+	tests := []struct {
+		policy    KeyPolicy
+		normalize bool
+		key, want string
+		err       error
+	}{
+		{AllowKeys, false, "foo", "foo", nil},
+		{AllowKeys, false, "", "", errKeyEmpty},
+		{AllowKeys, false, "fo\to", "", errKeyInvalid},
+		{AllowKeys, false, "caf\u00e9", "caf\u00e9", nil},
+		{AllowKeys, false, "cafe\u0301", "cafe\u0301", nil},
+		{AllowKeys, true, "cafe\u0301", "caf\u00e9", nil},
+		{AllowKeys, true, "\u0301e", "\u0301e", nil},
+		{TransliterateKeys, false, "caf\u00e9", "cafe", nil},
+		{TransliterateKeys, false, "cafe\u0301", "cafe", nil},
+		{TransliterateKeys, false, "Straße.Œuvre", "Strasse.OEuvre", nil},
+		{TransliterateKeys, false, "日本", "__", nil},
+		{TransliterateKeys, false, "a×b÷c", "a_b_c", nil},
+		{RejectKeys, false, "foo", "foo", nil},
+		{RejectKeys, false, "caf\u00e9", "", errKeyNotASCII},
+	}
+
+	defer SetKeyPolicy(AllowKeys)
+	defer SetKeyNormalization(false)
+	for _, tt := range tests {
+		SetKeyPolicy(tt.policy)
+		SetKeyNormalization(tt.normalize)
+		got, err := checkKey(tt.key)
+		if got != tt.want || err != tt.err {
+			t.Errorf("checkKey(%q) = (%q, %v) with policy %d and "+
+				"normalization %t, want (%q, %v)", tt.key, got, err,
+				tt.policy, tt.normalize, tt.want, tt.err)
+		}
+	}
+}
+
+func TestKeyPolicy(t *testing.T) {
+	SetKeyNormalization(true)
+	defer SetKeyNormalization(false)
+
+	expect(t, func() {
+		Event("caf\u00e9")
+		Event("cafe\u0301")
+		Info("foo", "pre\u0301nom", "Zoe\u0301")
+	}, []string{
+		"EVENT caf\u00e9",
+		"EVENT caf\u00e9",
+		"INFO  foo\t| pr\u00e9nom=\"Zoe\u0301\"",
+	})
+}
@@ -0,0 +1,200 @@
+package say
+
+// The tables below are derived from the Unicode canonical decompositions of the
+// Latin-1 Supplement and Latin Extended-A blocks.
+
+// latinASCII maps the letters from U+00C0 to U+017F to ASCII. The only symbols
+// of the range, × and ÷, are mapped to underscores like the other non-ASCII
+// characters.
+var latinASCII = [...]string{
+	"A", "A", "A", "A", "A", "A", "AE", "C", // U+00C0
+	"E", "E", "E", "E", "I", "I", "I", "I", // U+00C8
+	"D", "N", "O", "O", "O", "O", "O", "_", // U+00D0
+	"O", "U", "U", "U", "U", "Y", "TH", "ss", // U+00D8
+	"a", "a", "a", "a", "a", "a", "ae", "c", // U+00E0
+	"e", "e", "e", "e", "i", "i", "i", "i", // U+00E8
+	"d", "n", "o", "o", "o", "o", "o", "_", // U+00F0
+	"o", "u", "u", "u", "u", "y", "th", "y", // U+00F8
+	"A", "a", "A", "a", "A", "a", "C", "c", // U+0100
+	"C", "c", "C", "c", "C", "c", "D", "d", // U+0108
+	"D", "d", "E", "e", "E", "e", "E", "e", // U+0110
+	"E", "e", "E", "e", "G", "g", "G", "g", // U+0118
+	"G", "g", "G", "g", "H", "h", "H", "h", // U+0120
+	"I", "i", "I", "i", "I", "i", "I", "i", // U+0128
+	"I", "i", "IJ", "ij", "J", "j", "K", "k", // U+0130
+	"k", "L", "l", "L", "l", "L", "l", "L", // U+0138
+	"l", "L", "l", "N", "n", "N", "n", "N", // U+0140
+	"n", "n", "N", "n", "O", "o", "O", "o", // U+0148
+	"O", "o", "OE", "oe", "R", "r", "R", "r", // U+0150
+	"R", "r", "S", "s", "S", "s", "S", "s", // U+0158
+	"S", "s", "T", "t", "T", "t", "T", "t", // U+0160
+	"U", "u", "U", "u", "U", "u", "U", "u", // U+0168
+	"U", "u", "U", "u", "W", "w", "Y", "y", // U+0170
+	"Y", "Z", "z", "Z", "z", "Z", "z", "s", // U+0178
+}
+
+// latinCompositions maps an ASCII letter followed by a combining mark to the
+// precomposed letter of the Latin-1 Supplement and Latin Extended-A blocks.
+var latinCompositions = map[[2]rune]rune{
+	{'A', 0x0300}: 0x00C0, // À
+	{'A', 0x0301}: 0x00C1, // Á
+	{'A', 0x0302}: 0x00C2, // Â
+	{'A', 0x0303}: 0x00C3, // Ã
+	{'A', 0x0308}: 0x00C4, // Ä
+	{'A', 0x030A}: 0x00C5, // Å
+	{'C', 0x0327}: 0x00C7, // Ç
+	{'E', 0x0300}: 0x00C8, // È
+	{'E', 0x0301}: 0x00C9, // É
+	{'E', 0x0302}: 0x00CA, // Ê
+	{'E', 0x0308}: 0x00CB, // Ë
+	{'I', 0x0300}: 0x00CC, // Ì
+	{'I', 0x0301}: 0x00CD, // Í
+	{'I', 0x0302}: 0x00CE, // Î
+	{'I', 0x0308}: 0x00CF, // Ï
+	{'N', 0x0303}: 0x00D1, // Ñ
+	{'O', 0x0300}: 0x00D2, // Ò
+	{'O', 0x0301}: 0x00D3, // Ó
+	{'O', 0x0302}: 0x00D4, // Ô
+	{'O', 0x0303}: 0x00D5, // Õ
+	{'O', 0x0308}: 0x00D6, // Ö
+	{'U', 0x0300}: 0x00D9, // Ù
+	{'U', 0x0301}: 0x00DA, // Ú
+	{'U', 0x0302}: 0x00DB, // Û
+	{'U', 0x0308}: 0x00DC, // Ü
+	{'Y', 0x0301}: 0x00DD, // Ý
+	{'a', 0x0300}: 0x00E0, // à
+	{'a', 0x0301}: 0x00E1, // á
+	{'a', 0x0302}: 0x00E2, // â
+	{'a', 0x0303}: 0x00E3, // ã
+	{'a', 0x0308}: 0x00E4, // ä
+	{'a', 0x030A}: 0x00E5, // å
+	{'c', 0x0327}: 0x00E7, // ç
+	{'e', 0x0300}: 0x00E8, // è
+	{'e', 0x0301}: 0x00E9, // é
+	{'e', 0x0302}: 0x00EA, // ê
+	{'e', 0x0308}: 0x00EB, // ë
+	{'i', 0x0300}: 0x00EC, // ì
+	{'i', 0x0301}: 0x00ED, // í
+	{'i', 0x0302}: 0x00EE, // î
+	{'i', 0x0308}: 0x00EF, // ï
+	{'n', 0x0303}: 0x00F1, // ñ
+	{'o', 0x0300}: 0x00F2, // ò
+	{'o', 0x0301}: 0x00F3, // ó
+	{'o', 0x0302}: 0x00F4, // ô
+	{'o', 0x0303}: 0x00F5, // õ
+	{'o', 0x0308}: 0x00F6, // ö
+	{'u', 0x0300}: 0x00F9, // ù
+	{'u', 0x0301}: 0x00FA, // ú
+	{'u', 0x0302}: 0x00FB, // û
+	{'u', 0x0308}: 0x00FC, // ü
+	{'y', 0x0301}: 0x00FD, // ý
+	{'y', 0x0308}: 0x00FF, // ÿ
+	{'A', 0x0304}: 0x0100, // Ā
+	{'a', 0x0304}: 0x0101, // ā
+	{'A', 0x0306}: 0x0102, // Ă
+	{'a', 0x0306}: 0x0103, // ă
+	{'A', 0x0328}: 0x0104, // Ą
+	{'a', 0x0328}: 0x0105, // ą
+	{'C', 0x0301}: 0x0106, // Ć
+	{'c', 0x0301}: 0x0107, // ć
+	{'C', 0x0302}: 0x0108, // Ĉ
+	{'c', 0x0302}: 0x0109, // ĉ
+	{'C', 0x0307}: 0x010A, // Ċ
+	{'c', 0x0307}: 0x010B, // ċ
+	{'C', 0x030C}: 0x010C, // Č
+	{'c', 0x030C}: 0x010D, // č
+	{'D', 0x030C}: 0x010E, // Ď
+	{'d', 0x030C}: 0x010F, // ď
+	{'E', 0x0304}: 0x0112, // Ē
+	{'e', 0x0304}: 0x0113, // ē
+	{'E', 0x0306}: 0x0114, // Ĕ
+	{'e', 0x0306}: 0x0115, // ĕ
+	{'E', 0x0307}: 0x0116, // Ė
+	{'e', 0x0307}: 0x0117, // ė
+	{'E', 0x0328}: 0x0118, // Ę
+	{'e', 0x0328}: 0x0119, // ę
+	{'E', 0x030C}: 0x011A, // Ě
+	{'e', 0x030C}: 0x011B, // ě
+	{'G', 0x0302}: 0x011C, // Ĝ
+	{'g', 0x0302}: 0x011D, // ĝ
+	{'G', 0x0306}: 0x011E, // Ğ
+	{'g', 0x0306}: 0x011F, // ğ
+	{'G', 0x0307}: 0x0120, // Ġ
+	{'g', 0x0307}: 0x0121, // ġ
+	{'G', 0x0327}: 0x0122, // Ģ
+	{'g', 0x0327}: 0x0123, // ģ
+	{'H', 0x0302}: 0x0124, // Ĥ
+	{'h', 0x0302}: 0x0125, // ĥ
+	{'I', 0x0303}: 0x0128, // Ĩ
+	{'i', 0x0303}: 0x0129, // ĩ
+	{'I', 0x0304}: 0x012A, // Ī
+	{'i', 0x0304}: 0x012B, // ī
+	{'I', 0x0306}: 0x012C, // Ĭ
+	{'i', 0x0306}: 0x012D, // ĭ
+	{'I', 0x0328}: 0x012E, // Į
+	{'i', 0x0328}: 0x012F, // į
+	{'I', 0x0307}: 0x0130, // İ
+	{'J', 0x0302}: 0x0134, // Ĵ
+	{'j', 0x0302}: 0x0135, // ĵ
+	{'K', 0x0327}: 0x0136, // Ķ
+	{'k', 0x0327}: 0x0137, // ķ
+	{'L', 0x0301}: 0x0139, // Ĺ
+	{'l', 0x0301}: 0x013A, // ĺ
+	{'L', 0x0327}: 0x013B, // Ļ
+	{'l', 0x0327}: 0x013C, // ļ
+	{'L', 0x030C}: 0x013D, // Ľ
+	{'l', 0x030C}: 0x013E, // ľ
+	{'N', 0x0301}: 0x0143, // Ń
+	{'n', 0x0301}: 0x0144, // ń
+	{'N', 0x0327}: 0x0145, // Ņ
+	{'n', 0x0327}: 0x0146, // ņ
+	{'N', 0x030C}: 0x0147, // Ň
+	{'n', 0x030C}: 0x0148, // ň
+	{'O', 0x0304}: 0x014C, // Ō
+	{'o', 0x0304}: 0x014D, // ō
+	{'O', 0x0306}: 0x014E, // Ŏ
+	{'o', 0x0306}: 0x014F, // ŏ
+	{'O', 0x030B}: 0x0150, // Ő
+	{'o', 0x030B}: 0x0151, // ő
+	{'R', 0x0301}: 0x0154, // Ŕ
+	{'r', 0x0301}: 0x0155, // ŕ
+	{'R', 0x0327}: 0x0156, // Ŗ
+	{'r', 0x0327}: 0x0157, // ŗ
+	{'R', 0x030C}: 0x0158, // Ř
+	{'r', 0x030C}: 0x0159, // ř
+	{'S', 0x0301}: 0x015A, // Ś
+	{'s', 0x0301}: 0x015B, // ś
+	{'S', 0x0302}: 0x015C, // Ŝ
+	{'s', 0x0302}: 0x015D, // ŝ
+	{'S', 0x0327}: 0x015E, // Ş
+	{'s', 0x0327}: 0x015F, // ş
+	{'S', 0x030C}: 0x0160, // Š
+	{'s', 0x030C}: 0x0161, // š
+	{'T', 0x0327}: 0x0162, // Ţ
+	{'t', 0x0327}: 0x0163, // ţ
+	{'T', 0x030C}: 0x0164, // Ť
+	{'t', 0x030C}: 0x0165, // ť
+	{'U', 0x0303}: 0x0168, // Ũ
+	{'u', 0x0303}: 0x0169, // ũ
+	{'U', 0x0304}: 0x016A, // Ū
+	{'u', 0x0304}: 0x016B, // ū
+	{'U', 0x0306}: 0x016C, // Ŭ
+	{'u', 0x0306}: 0x016D, // ŭ
+	{'U', 0x030A}: 0x016E, // Ů
+	{'u', 0x030A}: 0x016F, // ů
+	{'U', 0x030B}: 0x0170, // Ű
+	{'u', 0x030B}: 0x0171, // ű
+	{'U', 0x0328}: 0x0172, // Ų
+	{'u', 0x0328}: 0x0173, // ų
+	{'W', 0x0302}: 0x0174, // Ŵ
+	{'w', 0x0302}: 0x0175, // ŵ
+	{'Y', 0x0302}: 0x0176, // Ŷ
+	{'y', 0x0302}: 0x0177, // ŷ
+	{'Y', 0x0308}: 0x0178, // Ÿ
+	{'Z', 0x0301}: 0x0179, // Ź
+	{'z', 0x0301}: 0x017A, // ź
+	{'Z', 0x0307}: 0x017B, // Ż
+	{'z', 0x0307}: 0x017C, // ż
+	{'Z', 0x030C}: 0x017D, // Ž
+	{'z', 0x030C}: 0x017E, // ž
+}
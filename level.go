@@ -0,0 +1,63 @@
+package say
+
+import (
+	"errors"
+	"strings"
+)
+
+var errLevelPrefixType = errors.New("say: level prefixes only accept log message types")
+
+// A LevelPrefix maps the lines starting with Prefix to a message type when
+// capturing the standard log. Prefixes are matched regardless of case.
+type LevelPrefix struct {
+	Prefix string
+	Type   Type
+}
+
+// DefaultLevelPrefixes are the level prefixes used by CaptureStandardLog by
+// default.
+var DefaultLevelPrefixes = []LevelPrefix{
+	{"ERROR:", TypeError},
+	{"[ERROR]", TypeError},
+	{"WARNING:", TypeWarning},
+	{"[WARNING]", TypeWarning},
+	{"WARN:", TypeWarning},
+	{"[WARN]", TypeWarning},
+	{"INFO:", TypeInfo},
+	{"[INFO]", TypeInfo},
+	{"DEBUG:", TypeDebug},
+	{"[DEBUG]", TypeDebug},
+}
+
+var levelPrefixes = DefaultLevelPrefixes
+
+// SetLevelPrefixes sets the prefixes that CaptureStandardLog uses to detect
+// the type of the captured lines. The first matching prefix wins and is removed
+// from the line. SetLevelPrefixes(nil) captures all the lines as INFO messages.
+//
+// The types must be TypeDebug, TypeInfo, TypeWarning, TypeError or TypeFatal,
+// otherwise SetLevelPrefixes panics.
+//
+// This function must not be called concurrently with the other functions of
+// this package.
+func SetLevelPrefixes(prefixes []LevelPrefix) {
+	for _, p := range prefixes {
+		switch p.Type {
+		case TypeDebug, TypeInfo, TypeWarning, TypeError, TypeFatal:
+		default:
+			panic(errLevelPrefixType)
+		}
+	}
+	levelPrefixes = prefixes
+}
+
+// detectLevel returns the type of line according to the level prefixes, or
+// typ if it has none, and the line without its prefix.
+func detectLevel(typ Type, line string) (Type, string) {
+	for _, p := range levelPrefixes {
+		if len(line) >= len(p.Prefix) && strings.EqualFold(line[:len(p.Prefix)], p.Prefix) {
+			return p.Type, strings.TrimLeft(line[len(p.Prefix):], " ")
+		}
+	}
+	return typ, line
+}
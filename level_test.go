@@ -0,0 +1,37 @@
+package say
+
+import (
+	"log"
+	"testing"
+)
+
+func TestCaptureStandardLogLevels(t *testing.T) {
+	defer SetLevelPrefixes(DefaultLevelPrefixes)
+
+	expect(t, func() {
+		l := NewLogger(SkipStackFrames(-1))
+		l.CaptureStandardLog()
+		log.Print("ERROR: foo")
+		log.Print("[WARN] foo")
+		log.Print("warning: foo")
+		log.Print("Debug: foo")
+		log.Print("errors are fine")
+		SetLevelPrefixes([]LevelPrefix{{"E ", TypeError}})
+		log.Print("E foo")
+		log.Print("ERROR: foo")
+	}, []string{
+		"ERROR foo",
+		"WARN  foo",
+		"WARN  foo",
+		"INFO  errors are fine",
+		"ERROR foo",
+		"INFO  ERROR: foo",
+	})
+
+	defer func() {
+		if err := recover(); err != errLevelPrefixType {
+			t.Errorf("SetLevelPrefixes(TypeEvent) = %v, want %v", err, errLevelPrefixType)
+		}
+	}()
+	SetLevelPrefixes([]LevelPrefix{{"E ", TypeEvent}})
+}
@@ -0,0 +1,64 @@
+package say
+
+import (
+	"strconv"
+	"unicode/utf8"
+)
+
+var (
+	maxContentSize int
+	maxValueSize   int
+)
+
+// SetSizeLimits sets the maximum size in bytes of the content of messages and
+// of the string values of data pairs. Longer contents and values are truncated
+// and end with a "...(truncated, N bytes)" marker, N being the number of bytes
+// removed. A limit of 0, the default, means no limit.
+//
+// This prevents a huge payload logged by accident from overwhelming the
+// systems processing the logs.
+func SetSizeLimits(content, value int) {
+	mu.Lock()
+	maxContentSize = content
+	maxValueSize = value
+	mu.Unlock()
+}
+
+// truncate truncates the content and the data values of msg to the given
+// sizes.
+func truncate(msg *Message, content, value int) {
+	if content > 0 {
+		msg.Content = truncateString(msg.Content, content)
+	}
+	if value > 0 {
+		truncateData(msg.Data, value)
+	}
+}
+
+// truncateData truncates the string values of data. Since the Data of a group
+// may be shared, it is copied before being truncated.
+func truncateData(data Data, max int) {
+	for i, kv := range data {
+		switch v := kv.Value.(type) {
+		case string:
+			data[i].Value = truncateString(v, max)
+		case Data:
+			v = append(Data(nil), v...)
+			truncateData(v, max)
+			data[i].Value = v
+		}
+	}
+}
+
+// truncateString truncates s to max bytes, without splitting a UTF-8
+// sequence, and appends a truncation marker.
+func truncateString(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	n := max
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "...(truncated, " + strconv.Itoa(len(s)-n) + " bytes)"
+}
@@ -0,0 +1,21 @@
+package say
+
+import "testing"
+
+func TestSetSizeLimits(t *testing.T) {
+	defer SetSizeLimits(0, 0)
+
+	expect(t, func() {
+		SetSizeLimits(10, 4)
+		Info("0123456789abc", "a", "abcdef", "b", "abc", "c", 123456)
+		Info("foo", Group("g", "a", "héllo"))
+		Info("aaaaaaaaaé")
+		SetSizeLimits(0, 0)
+		Info("0123456789abc", "a", "abcdef")
+	}, []string{
+		`INFO  0123456789...(truncated, 3 bytes)	| a="abcd...(truncated, 2 bytes)" b="abc" c=123456`,
+		`INFO  foo	| g.a="hél...(truncated, 2 bytes)"`,
+		`INFO  aaaaaaaaa...(truncated, 2 bytes)`,
+		`INFO  0123456789abc	| a="abcdef"`,
+	})
+}
@@ -21,51 +21,116 @@ const (
 	TypeWarning Type = "WARN "
 	TypeError   Type = "ERROR"
 	TypeFatal   Type = "FATAL"
+	TypeUser    Type = "USER "
+	TypeAudit   Type = "AUDIT"
+	TypeHisto   Type = "HISTO"
+	TypeUnique  Type = "UNIQ "
+	TypeSummary Type = "SUMRY"
 )
 
+// typeBit returns the bit representing typ in a set of types.
+func typeBit(typ Type) uint32 {
+	switch typ {
+	case TypeEvent:
+		return 1 << 0
+	case TypeValue:
+		return 1 << 1
+	case TypeGauge:
+		return 1 << 2
+	case TypeDebug:
+		return 1 << 3
+	case TypeInfo:
+		return 1 << 4
+	case TypeWarning:
+		return 1 << 5
+	case TypeError:
+		return 1 << 6
+	case TypeFatal:
+		return 1 << 7
+	case TypeUser:
+		return 1 << 8
+	case TypeAudit:
+		return 1 << 9
+	case TypeHisto:
+		return 1 << 10
+	case TypeUnique:
+		return 1 << 11
+	case TypeSummary:
+		return 1 << 12
+	}
+	return 0
+}
+
 // A Message represents a log line or a metric.
 type Message struct {
-	Type    Type
+	Type Type
+
+	// Key is the key of an EVENT, VALUE, GAUGE, HISTO or UNIQ message, the
+	// code of a USER message or the action of an AUDIT message.
+	Key string
+	// Value is the numeric value of an EVENT, VALUE, GAUGE or HISTO message.
+	// It is 1 for an EVENT without an increment.
+	Value float64
+	// Unit is the unit of Value, e.g. "ms" for durations.
+	Unit string
+
+	// Content is the text of a log message, the user-facing text of a USER
+	// message, the ID of a UNIQ message or the value of a metric when it is
+	// not a number.
 	Content string
 	Data    Data
+
+	// Tenant is the tenant on behalf of which the message has been sent. See
+	// Logger.ForTenant.
+	Tenant string
+
+	// TTL is how long the message remains valid, or 0 if it does not
+	// expire. See the TTL option.
+	TTL time.Duration
+
+	jsonData bool      // Whether Data is written as a JSON object.
+	out      io.Writer // The output of the Logger, if any.
+	stack    string    // The stack trace, once rendered.
+	pcs      []uintptr // The stack trace, until it is rendered.
+	redactor Redactor  // Applied to the stack trace when it is rendered.
+	size     int       // The memory reserved in the listener queue, if any.
 }
 
-// Key returns the key of an EVENT, VALUE or GAUGE message.
-func (m *Message) Key() string {
-	i := strings.IndexByte(m.Content, ':')
-	if i == -1 {
-		return m.Content
+// isMetric reports whether m is an EVENT, VALUE, GAUGE, HISTO or UNIQ message.
+func (m *Message) isMetric() bool {
+	switch m.Type {
+	case TypeEvent, TypeValue, TypeGauge, TypeHisto, TypeUnique:
+		return true
 	}
-	return m.Content[:i]
+	return false
 }
 
-// Value returns the value of an EVENT, VALUE or GAUGE message.
-func (m *Message) Value() string {
-	i := strings.IndexByte(m.Content, ':')
-	if i == -1 {
-		return ""
-	}
-	return m.Content[i+1:]
+// isNumeric reports whether m is a metric having a numeric value.
+func (m *Message) isNumeric() bool {
+	return m.isMetric() && m.Type != TypeUnique && m.Content == ""
+}
+
+// hasDefaultIncrement reports whether m is an EVENT incremented by 1, whose
+// value is omitted in the output.
+func (m *Message) hasDefaultIncrement() bool {
+	return m.Type == TypeEvent && m.Value == 1 && m.Unit == ""
 }
 
 // Int returns the value as an integer. If the value is not an integer, ok is
 // false. If the value is a duration in milliseconds, return the number of
 // milliseconds. It returns 1 if the message is an EVENT without an increment.
 func (m *Message) Int() (n int, ok bool) {
-	v := m.Value()
-	if v == "" {
-		if m.Type == TypeEvent {
-			return 1, true
-		}
-		return 0, false
+	if m.isNumeric() {
+		return int(m.Value), true
 	}
-	if strings.HasSuffix(v, "ms") {
-		v = v[:len(v)-2]
+	if !m.isMetric() {
+		return 0, false
 	}
-	if i, err := strconv.Atoi(v); err == nil {
+
+	if i, err := strconv.Atoi(m.Content); err == nil {
 		return i, true
 	}
-	if f, err := strconv.ParseFloat(v, 64); err == nil {
+	if f, err := strconv.ParseFloat(m.Content, 64); err == nil {
 		return int(f), true
 	}
 	return 0, false
@@ -75,30 +140,67 @@ func (m *Message) Int() (n int, ok bool) {
 // false. If the value is a duration in milliseconds, return the number of
 // milliseconds. It returns 1 if the message is an EVENT without an increment.
 func (m *Message) Float64() (float64, bool) {
-	v := m.Value()
-	if v == "" {
-		if m.Type == TypeEvent {
-			return 1, true
-		}
-		return 0, false
+	if m.isNumeric() {
+		return m.Value, true
 	}
-	if strings.HasSuffix(v, "ms") {
-		v = v[:len(v)-2]
+	if !m.isMetric() {
+		return 0, false
 	}
-	if f, err := strconv.ParseFloat(v, 64); err == nil {
+
+	if f, err := strconv.ParseFloat(m.Content, 64); err == nil {
 		return f, true
 	}
 	return 0, false
 }
 
+// ValueUnit returns the numeric value of a metric and its unit, e.g. "ms" for
+// a duration or "B" for a value sent with ValueUnit. The unit is empty for the
+// values without a unit. If the value is not a number, ok is false.
+func (m *Message) ValueUnit() (value float64, unit string, ok bool) {
+	if m.isNumeric() {
+		return m.Value, m.Unit, true
+	}
+	if !m.isMetric() {
+		return 0, "", false
+	}
+
+	i := strings.LastIndexAny(m.Content, "0123456789.") + 1
+	value, err := strconv.ParseFloat(m.Content[:i], 64)
+	if err != nil || !isValidUnit(m.Content[i:]) {
+		return 0, "", false
+	}
+	return value, m.Content[i:], true
+}
+
+// GaugeDelta returns the value of a GAUGE message sent with GaugeAdd or
+// GaugeSub, which is relative to the current value of the gauge. If the
+// message is not a gauge delta, e.g. an absolute gauge sent with Gauge, ok is
+// false.
+func (m *Message) GaugeDelta() (delta float64, ok bool) {
+	if m.Type != TypeGauge || m.isNumeric() {
+		return 0, false
+	}
+	if !strings.HasPrefix(m.Content, "+") && !strings.HasPrefix(m.Content, "-") {
+		return 0, false
+	}
+	delta, err := strconv.ParseFloat(m.Content, 64)
+	return delta, err == nil
+}
+
 // Duration returns the duration of a VALUE message. If the value is not a
 // duration, ok is false.
 func (m *Message) Duration() (time.Duration, bool) {
-	v := m.Value()
-	if v == "" {
+	if m.isNumeric() {
+		if m.Unit != "ms" {
+			return 0, false
+		}
+		return time.Duration(m.Value * float64(time.Millisecond)), true
+	}
+	if !m.isMetric() {
 		return 0, false
 	}
-	d, err := time.ParseDuration(v)
+
+	d, err := time.ParseDuration(m.Content)
 	return d, err == nil
 }
 
@@ -107,21 +209,28 @@ func (m *Message) Error() string {
 	if m.Type != TypeError && m.Type != TypeFatal {
 		return ""
 	}
-
-	i := strings.LastIndex(m.Content, "\n\n")
-	if i == -1 {
-		return m.Content
-	}
-	return m.Content[:i]
+	return m.Content
 }
 
-// StackTrace returns the stack trace of an ERROR or FATAL message.
+// StackTrace returns the stack trace of an ERROR or FATAL message. The stack
+// trace is not part of Content: it is rendered the first time StackTrace is
+// called, when it has been captured with DeferStackTraces or by the error.
 func (m *Message) StackTrace() string {
-	i := strings.LastIndex(m.Content, "\n\n")
-	if i == -1 {
-		return ""
+	if len(m.pcs) > 0 {
+		m.stack = symbolize(m.pcs)
+		m.pcs = nil
+		if m.redactor != nil {
+			m.stack = m.redactor.RedactContent(m.stack)
+		}
 	}
-	return m.Content[i+2:]
+	return m.stack
+}
+
+// DiscardStackTrace removes the stack trace of the message, e.g. before writing
+// it to a sink that does not need it.
+func (m *Message) DiscardStackTrace() {
+	m.stack = ""
+	m.pcs = nil
 }
 
 // DataString returns the raw data string associated with the message.
@@ -129,32 +238,29 @@ func (m *Message) StackTrace() string {
 
 // WriteTo writes the Message to w.
 func (m *Message) WriteTo(w io.Writer) (int64, error) {
-	t := now()
-	buf := getBuffer()
+	return m.writeTo(w, true)
+}
 
-	// Print the timestamp.
-	buf.appendDigits(t.Year(), 4)
-	buf.appendByte('-')
-	buf.appendDigits(int(t.Month()), 2)
-	buf.appendByte('-')
-	buf.appendDigits(t.Day(), 2)
-	buf.appendByte(' ')
-	buf.appendDigits(t.Hour(), 2)
-	buf.appendByte(':')
-	buf.appendDigits(t.Minute(), 2)
-	buf.appendByte(':')
-	buf.appendDigits(t.Second(), 2)
-	buf.appendByte('.')
-	buf.appendDigits(t.Nanosecond()/int(time.Millisecond), 3)
+// WriteWithoutTimestampTo writes the Message to w like WriteTo, without the
+// timestamp. Use it when the output must not depend on the time, e.g. in
+// golden files.
+func (m *Message) WriteWithoutTimestampTo(w io.Writer) (int64, error) {
+	return m.writeTo(w, false)
+}
+
+func (m *Message) writeTo(w io.Writer, timestamp bool) (int64, error) {
+	buf := getBuffer()
+	if timestamp {
+		buf.appendTimestamp(now())
+		buf.appendByte(' ')
+	}
 
 	// Print the message.
-	buf.appendByte(' ')
 	buf.appendString(string(m.Type))
 	buf.appendByte(' ')
-	buf.appendString(m.Content)
-	if len(m.Data) > 0 {
-		buf.appendData(m.Data)
-	}
+	buf.appendContent(m, false)
+	buf.appendStackTrace(m, false)
+	buf.appendMessageData(m)
 	buf.appendByte('\n')
 
 	n, err := w.Write(buf.buf)
@@ -167,11 +273,33 @@ func (m *Message) WriteJSONTo(w io.Writer) (int, error) {
 	buf := getBuffer()
 
 	buf.appendString(`{"timestamp": "`)
-	buf.appendString(now().Format(time.RFC3339Nano))
+	buf.buf = inTimeLocation(now()).AppendFormat(buf.buf, time.RFC3339Nano)
 	buf.appendString(`", "type": "`)
 	buf.appendString(strings.TrimSuffix(string(m.Type), " "))
-	buf.appendString(`", "content": `)
-	buf.appendQuote(m.Content)
+	buf.appendString(`"`)
+	if m.Key != "" {
+		buf.appendString(`, "key": `)
+		buf.appendJSONString(m.Key)
+	}
+	if m.isNumeric() {
+		buf.appendString(`, "value": `)
+		if isFinite(m.Value) {
+			buf.appendNumber(m.Value)
+		} else {
+			buf.appendString("null")
+		}
+		if m.Unit != "" {
+			buf.appendString(`, "unit": `)
+			buf.appendJSONString(m.Unit)
+		}
+	} else if m.Key == "" || m.Content != "" {
+		buf.appendString(`, "content": `)
+		buf.appendJSONString(m.Content)
+	}
+	if st := m.StackTrace(); st != "" {
+		buf.appendString(`, "stack_trace": `)
+		buf.appendJSONString(st)
+	}
 
 	data := m.Data
 	if len(data) > 0 {
@@ -183,9 +311,9 @@ func (m *Message) WriteJSONTo(w io.Writer) (int, error) {
 				continue
 			}
 			buf.appendString(", ")
-			buf.appendQuote(kv.Key)
+			buf.appendJSONString(kv.Key)
 			buf.appendString(": ")
-			buf.appendDataValue(kv.Value)
+			buf.appendDataValue(kv.Key, kv.Value, true)
 			written = true
 		}
 
@@ -201,16 +329,11 @@ func (m *Message) WriteJSONTo(w io.Writer) (int, error) {
 }
 
 func (m *Message) skipKey(d Data, i int) bool {
-	key := d[i].Key
-	if key == "timestamp" || key == "type" || key == "content" {
+	switch d[i].Key {
+	case "timestamp", "type", "key", "value", "unit", "content", "stack_trace":
 		return true
 	}
-	for _, kv := range d[i+1:] {
-		if key == kv.Key {
-			return true
-		}
-	}
-	return false
+	return d.isOverridden(i)
 }
 
 var msgPool = sync.Pool{
@@ -224,6 +347,6 @@ func getMessage() *Message {
 }
 
 func putMessage(msg *Message) {
-	msg.Data = msg.Data[:0]
+	*msg = Message{Data: msg.Data[:0]}
 	msgPool.Put(msg)
 }
@@ -5,6 +5,8 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"gopkg.in/say.v0/sayclock"
 )
 
 func TestMessageType(t *testing.T) {
@@ -14,7 +16,7 @@ func TestMessageType(t *testing.T) {
 		{func() { Value("foo", 42) }, TypeValue},
 		{func() { NewTiming().Say("foo") }, TypeValue},
 		{func() { Gauge("foo", 42) }, TypeGauge},
-		{func() { Debug("foo") }, TypeDebug},
+		debugTest(func() { Debug("foo") }, TypeDebug),
 		{func() { Info("foo") }, TypeInfo},
 		{func() { Warning("foo") }, TypeWarning},
 		{func() { Error("foo") }, TypeError},
@@ -71,25 +73,34 @@ func TestMessageKey(t *testing.T) {
 
 	testMessage(t, tests, func(m *Message, want interface{}) {
 		key := want.(string)
-		if m.Key() != key {
-			t.Errorf("Message.Key() = %q, want %q", m.Key(), key)
+		if m.Key != key {
+			t.Errorf("Message.Key = %q, want %q", m.Key, key)
 		}
 	})
 }
 
 func TestMessageValue(t *testing.T) {
+	type result struct {
+		value   float64
+		unit    string
+		content string
+	}
+
 	tests := []test{
-		{func() { Event("foo") }, ""},
-		{func() { Events(`fo"o`, 42) }, "42"},
-		{func() { Value("foo bar", 17.6) }, "17.6"},
-		{func() { NewTiming().Say("app.host.key") }, "0ms"},
-		{func() { Gauge("#!€", -25.5) }, "-25.5"},
+		{func() { Event("foo") }, result{1, "", ""}},
+		{func() { Events(`fo"o`, 42) }, result{42, "", ""}},
+		{func() { Value("foo bar", 17.6) }, result{17.6, "", ""}},
+		{func() { Value("foo bar", true) }, result{0, "", "true"}},
+		{func() { NewTiming().Say("app.host.key") }, result{0, "ms", ""}},
+		{func() { Gauge("#!€", -25.5) }, result{-25.5, "", ""}},
 	}
 
 	testMessage(t, tests, func(m *Message, want interface{}) {
-		value := want.(string)
-		if m.Value() != value {
-			t.Errorf("Message.Key() = %q, want %q", m.Value(), value)
+		res := want.(result)
+		if m.Value != res.value || m.Unit != res.unit || m.Content != res.content {
+			t.Errorf("Message (Value, Unit, Content) = (%g, %q, %q), "+
+				"want (%g, %q, %q)", m.Value, m.Unit, m.Content,
+				res.value, res.unit, res.content)
 		}
 	})
 }
@@ -169,6 +180,29 @@ func TestMessageDuration(t *testing.T) {
 	})
 }
 
+func TestMessageValueUnit(t *testing.T) {
+	type valueUnit struct {
+		value float64
+		unit  string
+		ok    bool
+	}
+	tests := []test{
+		{func() { ValueUnit("foo", 1234, "B") }, valueUnit{1234, "B", true}},
+		{func() { Value("foo", 1.5) }, valueUnit{1.5, "", true}},
+		{func() { NewTiming().Say("foo") }, valueUnit{0, "ms", true}},
+		{func() { Value("foo", "12kB") }, valueUnit{12, "kB", true}},
+		{func() { Value("foo", "bar") }, valueUnit{0, "", false}},
+		{func() { Info("12B") }, valueUnit{0, "", false}},
+	}
+
+	testMessage(t, tests, func(m *Message, want interface{}) {
+		value, unit, ok := m.ValueUnit()
+		if got := (valueUnit{value, unit, ok}); got != want {
+			t.Errorf("Message.ValueUnit() = %v, want %v", got, want)
+		}
+	})
+}
+
 func TestMessageError(t *testing.T) {
 	log := new(Logger)
 
@@ -223,8 +257,8 @@ func TestMessageWriteTo(t *testing.T) {
 			"2015-11-25 15:47:00.000 GAUGE foo\":-35\n"},
 		{func() { log.NewTiming().Say("foo") },
 			"2015-11-25 15:47:00.000 VALUE foo:0ms\n"},
-		{func() { log.Debug("foo") },
-			"2015-11-25 15:47:00.000 DEBUG foo\n"},
+		debugTest(func() { log.Debug("foo") },
+			"2015-11-25 15:47:00.000 DEBUG foo\n"),
 		{func() { log.Info("foo", "a", "b") },
 			"2015-11-25 15:47:00.000 INFO  foo\t| a=\"b\"\n"},
 		{func() { log.Warning("foo", "i", 1, "f", 3.5) },
@@ -252,21 +286,95 @@ func TestMessageWriteTo(t *testing.T) {
 	})
 }
 
+func TestMessageWriteWithoutTimestampTo(t *testing.T) {
+	m := &Message{Type: TypeInfo, Content: "foo\nbar", Data: Data{{"a", "b"}}}
+	buf := new(bytes.Buffer)
+	n, err := m.WriteWithoutTimestampTo(buf)
+	want := "INFO  foo\nbar\t| a=\"b\"\n"
+	if got := buf.String(); got != want || int(n) != len(want) || err != nil {
+		t.Errorf("Message.WriteWithoutTimestampTo = (%q, %d, %v), want (%q, %d, nil)",
+			got, n, err, want, len(want))
+	}
+}
+
+func TestSetTimeFormat(t *testing.T) {
+	defer SetTimeFormat(DefaultTimeLayout, nil)
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	tests := []struct {
+		layout string
+		loc    *time.Location
+		want   string
+	}{
+		{DefaultTimeLayout, nil, "2015-11-25 15:47:00.000"},
+		{time.RFC3339, nil, "2015-11-25T15:47:00Z"},
+		{time.RFC3339, paris, "2015-11-25T16:47:00+01:00"},
+		{EpochMillis, nil, "1448466420000"},
+	}
+	buf := new(bytes.Buffer)
+	for _, tt := range tests {
+		SetTimeFormat(tt.layout, tt.loc)
+		testMessage(t, []test{{func() { Info("foo") }, nil}}, func(m *Message, _ interface{}) {
+			m.WriteTo(buf)
+		})
+		want := tt.want + " INFO  foo\n"
+		if got := buf.String(); got != want {
+			t.Errorf("SetTimeFormat(%q, %v): got %q, want %q", tt.layout, tt.loc, got, want)
+		}
+		buf.Reset()
+	}
+
+	SetTimeFormat(time.RFC3339, paris)
+	testMessage(t, []test{{func() { Info("foo") }, nil}}, func(m *Message, _ interface{}) {
+		m.WriteJSONTo(buf)
+	})
+	want := "{\"timestamp\": \"2015-11-25T16:47:00+01:00\", \"type\": \"INFO\", \"content\": \"foo\"}\n"
+	if got := buf.String(); got != want {
+		t.Errorf("SetTimeFormat(%q, %v): got %q, want %q", time.RFC3339, paris, got, want)
+	}
+}
+
+func TestWithTimestamps(t *testing.T) {
+	SetClock(sayclock.NewFake(time.Date(2015, 11, 25, 15, 47, 0, 0, time.UTC)))
+	defer SetClock(sayclock.Real)
+	defer WithTimestamps("")
+	defer SetTimeFormat(DefaultTimeLayout, nil)
+
+	expect(t, func() {
+		WithTimestamps(DefaultTimeLayout)
+		Info("foo")
+		WithTimestamps(time.RFC3339)
+		Event("foo")
+		SetTimeFormat(DefaultTimeLayout, time.FixedZone("", 3600))
+		Info("foo")
+		WithTimestamps("")
+		Info("foo")
+	}, []string{
+		"2015-11-25 15:47:00.000 INFO  foo",
+		"2015-11-25T15:47:00Z EVENT foo",
+		"2015-11-25T16:47:00+01:00 INFO  foo",
+		"INFO  foo",
+	})
+}
+
 func TestMessageWriteJSONTo(t *testing.T) {
 	log := NewLogger(SkipStackFrames(-1))
 	tests := []test{
 		{func() { log.Event("foo") },
-			"{\"timestamp\": \"2015-11-25T15:47:00Z\", \"type\": \"EVENT\", \"content\": \"foo\"}\n"},
+			"{\"timestamp\": \"2015-11-25T15:47:00Z\", \"type\": \"EVENT\", \"key\": \"foo\", \"value\": 1}\n"},
 		{func() { log.Events("foo", 5) },
-			"{\"timestamp\": \"2015-11-25T15:47:00Z\", \"type\": \"EVENT\", \"content\": \"foo:5\"}\n"},
+			"{\"timestamp\": \"2015-11-25T15:47:00Z\", \"type\": \"EVENT\", \"key\": \"foo\", \"value\": 5}\n"},
 		{func() { log.Value("foo", 17.6) },
-			"{\"timestamp\": \"2015-11-25T15:47:00Z\", \"type\": \"VALUE\", \"content\": \"foo:17.6\"}\n"},
+			"{\"timestamp\": \"2015-11-25T15:47:00Z\", \"type\": \"VALUE\", \"key\": \"foo\", \"value\": 17.6}\n"},
 		{func() { log.Gauge(`foo"`, -35, "foo", "bar", "foo", "baz") },
-			"{\"timestamp\": \"2015-11-25T15:47:00Z\", \"type\": \"GAUGE\", \"content\": \"foo\\\":-35\", \"foo\": \"baz\"}\n"},
-		{func() { log.NewTiming().Say("foo", "timestamp", "skip") },
-			"{\"timestamp\": \"2015-11-25T15:47:00Z\", \"type\": \"VALUE\", \"content\": \"foo:0ms\"}\n"},
-		{func() { log.Debug("foo", "type", "skip") },
-			"{\"timestamp\": \"2015-11-25T15:47:00Z\", \"type\": \"DEBUG\", \"content\": \"foo\"}\n"},
+			"{\"timestamp\": \"2015-11-25T15:47:00Z\", \"type\": \"GAUGE\", \"key\": \"foo\\\"\", \"value\": -35, \"foo\": \"baz\"}\n"},
+		{func() { log.NewTiming().Say("foo", "timestamp", "skip", "unit", "skip") },
+			"{\"timestamp\": \"2015-11-25T15:47:00Z\", \"type\": \"VALUE\", \"key\": \"foo\", \"value\": 0, \"unit\": \"ms\"}\n"},
+		debugTest(func() { log.Debug("foo", "type", "skip") },
+			"{\"timestamp\": \"2015-11-25T15:47:00Z\", \"type\": \"DEBUG\", \"content\": \"foo\"}\n"),
 		{func() { log.Info("foo", "a", "b") },
 			"{\"timestamp\": \"2015-11-25T15:47:00Z\", \"type\": \"INFO\", \"content\": \"foo\", \"a\": \"b\"}\n"},
 		{func() { log.Warning("foo", "i", 1, "f", 3.5) },
@@ -294,15 +402,41 @@ func TestMessageWriteJSONTo(t *testing.T) {
 	})
 }
 
+func TestTimingAllocs(t *testing.T) {
+	SetListener(func(*Message) {})
+	defer SetListener(nil)
+
+	log := new(Logger)
+	timing := log.NewTiming()
+	timing.Say("foo") // Fill the pools.
+	Flush()
+
+	n := testing.AllocsPerRun(100, func() {
+		timing.Say("foo")
+		Flush()
+	})
+	if n != 0 {
+		t.Errorf("Timing.Say allocates %v times, want 0", n)
+	}
+}
+
 type test struct {
 	f    func()
 	want interface{}
 }
 
-func testMessage(t *testing.T, tests []test, h func(*Message, interface{})) {
-	now = func() time.Time {
-		return time.Date(2015, 11, 25, 15, 47, 0, 0, time.UTC)
+// debugTest returns a test sending a DEBUG message. It is skipped when the
+// DEBUG messages are discarded with the saydiscard_debug tag.
+func debugTest(f func(), want interface{}) test {
+	if discardDebug {
+		return test{}
 	}
+	return test{f, want}
+}
+
+func testMessage(t *testing.T, tests []test, h func(*Message, interface{})) {
+	SetClock(sayclock.NewFake(time.Date(2015, 11, 25, 15, 47, 0, 0, time.UTC)))
+	defer SetClock(sayclock.Real)
 
 	var wg sync.WaitGroup
 	n := 0
@@ -318,6 +452,13 @@ func testMessage(t *testing.T, tests []test, h func(*Message, interface{})) {
 	defer SetListener(nil)
 	defer SetDebug(false)
 
+	var run []test
+	for _, test := range tests {
+		if test.f != nil {
+			run = append(run, test)
+		}
+	}
+	tests = run
 	for _, test := range tests {
 		wg.Add(1)
 		test.f()
@@ -0,0 +1,31 @@
+package say
+
+// Must prints a FATAL message with the stack trace and exits the program with
+// the status 1 if err is not nil. Use it in initialization code where a failure
+// must abort the program:
+//
+//	say.Must(db.Ping())
+func Must(err error) {
+	if err != nil {
+		fatalExit(1, err, nil, 1)
+	}
+}
+
+// MustValue returns v if err is nil. Otherwise, it prints a FATAL message with
+// the stack trace and exits the program with the status 1:
+//
+//	cfg := say.MustValue(loadConfig(path))
+func MustValue[T any](v T, err error) T {
+	if err != nil {
+		fatalExit(1, err, nil, 1)
+	}
+	return v
+}
+
+// fatalExit prints a FATAL message with the package-level Logger, flushes the
+// message queue and exits with the given status. skip is the number of frames
+// between the caller to report and fatalExit.
+func fatalExit(code int, v interface{}, data []interface{}, skip int) {
+	defaultLogger.error(TypeFatal, v, data, skip)
+	exitWith(code)
+}
@@ -0,0 +1,49 @@
+package say
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMust(t *testing.T) {
+	var codes []int
+	exit = func(code int) {
+		codes = append(codes, code)
+	}
+	defer func() {
+		exit = func(int) {}
+	}()
+
+	expect(t, func() {
+		Must(nil)
+		Must(errors.New("oops"))
+		if v := MustValue(5, nil); v != 5 {
+			t.Errorf("MustValue(5, nil) = %d, want 5", v)
+		}
+		MustValue("", errors.New("no config"))
+	}, []string{
+		"FATAL oops",
+		"FATAL no config",
+	})
+	if len(codes) != 2 || codes[0] != 1 || codes[1] != 1 {
+		t.Errorf("exit codes = %v, want [1 1]", codes)
+	}
+}
+
+func TestMustStackTrace(t *testing.T) {
+	DisableStackTraces(false)
+	defer DisableStackTraces(true)
+
+	var st string
+	SetListener(func(m *Message) {
+		st = m.StackTrace()
+	})
+	Must(errors.New("oops"))
+	Flush()
+	SetListener(nil)
+
+	if i := strings.IndexByte(st, '('); i == -1 || !strings.HasSuffix(st[:i], ".TestMustStackTrace") {
+		t.Errorf("stack trace does not start at the caller of Must:\n%s", st)
+	}
+}
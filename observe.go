@@ -0,0 +1,33 @@
+package say
+
+import "strconv"
+
+// Observe prints a VALUE message and an INFO message sharing the same data,
+// so that a metric and the log line explaining it are sent in one call. Both
+// messages get an observation_id data pair with a random ID that correlates
+// them:
+//
+//	say.Observe("search.results", 42, "Search done", "query", q)
+//	// Output:
+//	VALUE search.results:42	| query="shoes" observation_id="5c1b2e8f0a9d3e47"
+//	INFO  Search done	| query="shoes" observation_id="5c1b2e8f0a9d3e47"
+func (l *Logger) Observe(name string, value interface{}, msg string, data ...interface{}) {
+	data = append(data[:len(data):len(data)], "observation_id", newObservationID())
+	l.keyValue(TypeValue, name, value, data)
+	l.send(TypeInfo, msg, data)
+}
+
+// Observe prints a VALUE message and an INFO message sharing the same data and
+// an observation_id data pair. See Logger.Observe.
+func Observe(name string, value interface{}, msg string, data ...interface{}) {
+	defaultLogger.Observe(name, value, msg, data...)
+}
+
+// newObservationID returns a random ID of 16 hexadecimal digits.
+func newObservationID() string {
+	id := strconv.FormatUint(randUint64(), 16)
+	for len(id) < 16 {
+		id = "0" + id
+	}
+	return id
+}
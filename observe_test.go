@@ -0,0 +1,41 @@
+package say
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestObserve(t *testing.T) {
+	SetRand(rand.New(rand.NewSource(1)))
+	id := newObservationID()
+	SetRand(rand.New(rand.NewSource(1)))
+	defer SetRand(nil)
+
+	expect(t, func() {
+		Observe("search.results", 42, "Search done", "query", "shoes")
+	}, []string{
+		`VALUE search.results:42	| query="shoes" observation_id="` + id + `"`,
+		`INFO  Search done	| query="shoes" observation_id="` + id + `"`,
+	})
+	if len(id) != 16 {
+		t.Errorf("observation ID %q has %d digits, want 16", id, len(id))
+	}
+
+	data := make([]interface{}, 2, 4)
+	data[0], data[1] = "a", 1
+	var ids []interface{}
+	SetListener(func(m *Message) {
+		v, _ := m.Data.Get("observation_id")
+		ids = append(ids, v)
+	})
+	NewLogger().Observe("foo", 1, "bar", data...)
+	NewLogger().Observe("foo", 1, "bar", data...)
+	Flush()
+	SetListener(nil)
+	if len(ids) != 4 || ids[0] != ids[1] || ids[2] != ids[3] || ids[0] == ids[2] {
+		t.Errorf("observation IDs = %v, want two pairs of distinct IDs", ids)
+	}
+	if spare := data[:4]; spare[2] != nil {
+		t.Errorf("Observe wrote %v in the data slice of its caller", spare[2])
+	}
+}
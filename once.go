@@ -0,0 +1,61 @@
+package say
+
+import "sync"
+
+var seen sync.Map
+
+// firstTime reports whether a message of the given type and text is sent for
+// the first time by the process.
+func firstTime(typ Type, v interface{}) bool {
+	buf := getBuffer()
+	buf.appendString(string(typ))
+	buf.appendValue(v)
+	_, loaded := seen.LoadOrStore(buf.String(), struct{}{})
+	return !loaded
+}
+
+// InfoOnce prints an INFO message only the first time it is called with this
+// message by the process. Use it for messages that would otherwise be repeated
+// on every request, e.g. configuration fallbacks.
+func (l *Logger) InfoOnce(msg interface{}, data ...interface{}) {
+	if firstTime(TypeInfo, msg) {
+		l.send(TypeInfo, msg, data)
+	}
+}
+
+// InfoOnce prints an INFO message only the first time it is called with this
+// message by the process. Use it for messages that would otherwise be repeated
+// on every request, e.g. configuration fallbacks.
+func InfoOnce(msg interface{}, data ...interface{}) {
+	defaultLogger.InfoOnce(msg, data...)
+}
+
+// WarningOnce prints a WARNING message only the first time it is called with
+// this message by the process. Use it for messages that would otherwise be
+// repeated on every request, e.g. deprecation warnings.
+func (l *Logger) WarningOnce(v interface{}, data ...interface{}) {
+	if firstTime(TypeWarning, v) {
+		l.send(TypeWarning, v, data)
+	}
+}
+
+// WarningOnce prints a WARNING message only the first time it is called with
+// this message by the process. Use it for messages that would otherwise be
+// repeated on every request, e.g. deprecation warnings.
+func WarningOnce(v interface{}, data ...interface{}) {
+	defaultLogger.WarningOnce(v, data...)
+}
+
+// ErrorOnce prints an ERROR message with the stack trace only the first time
+// it is called with this error message by the process.
+func (l *Logger) ErrorOnce(v interface{}, data ...interface{}) {
+	if firstTime(TypeError, v) {
+		l.error(TypeError, v, data, 1)
+	}
+}
+
+// ErrorOnce prints an ERROR message with the stack trace only the first time
+// it is called with this error message by the process.
+func ErrorOnce(v interface{}, data ...interface{}) {
+	defaultLogger.ErrorOnce(v, data...)
+}
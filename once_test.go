@@ -0,0 +1,31 @@
+package say
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOnce(t *testing.T) {
+	seen.Range(func(k, _ interface{}) bool {
+		seen.Delete(k)
+		return true
+	})
+
+	expect(t, func() {
+		log := NewLogger()
+		for i := 0; i < 3; i++ {
+			InfoOnce("using the default config", "i", i)
+			log.WarningOnce("Foo is deprecated")
+			ErrorOnce(errors.New("oops"))
+			log.ErrorOnce("oops")
+		}
+		Info("using the default config")
+		WarningOnce("using the default config")
+	}, []string{
+		`INFO  using the default config	| i=0`,
+		`WARN  Foo is deprecated`,
+		`ERROR oops`,
+		`INFO  using the default config`,
+		`WARN  using the default config`,
+	})
+}
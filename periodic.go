@@ -0,0 +1,32 @@
+package say
+
+import (
+	"time"
+
+	"gopkg.in/say.v0/sayclock"
+)
+
+// every calls f every interval of the clock in a goroutine, until stop is
+// called. stop waits for the goroutine to return so it must not be called
+// while holding a lock that f takes.
+func every(interval time.Duration, f func()) (stop func()) {
+	t := sayclock.NewTicker(clock, interval)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-t.C():
+				f()
+			case <-done:
+				t.Stop()
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
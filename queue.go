@@ -0,0 +1,69 @@
+package say
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+var (
+	queueMaxBytes int64 // Accessed atomically.
+	queueBytes    int64 // Accessed atomically.
+	queueDropped  int64 // Accessed atomically.
+)
+
+// SetQueueMemory limits the memory used by the messages waiting in the
+// listener queue to about max bytes, so that a burst of huge messages cannot
+// make the memory of the process balloon. The messages that would exceed the
+// limit are dropped and, once the queue has room again, a WARN message tells
+// how many were. AUDIT messages are never dropped.
+//
+// A max of 0 removes the limit, which is the default. The queue is always
+// limited to 1000 messages.
+func SetQueueMemory(max int) {
+	atomic.StoreInt64(&queueMaxBytes, int64(max))
+}
+
+// messageMemory returns an estimate of the memory used by msg.
+func messageMemory(msg *Message) int {
+	n := int(unsafe.Sizeof(*msg)) + len(msg.Key) + len(msg.Content) + len(msg.Unit) +
+		len(msg.Tenant) + len(msg.stack) + len(msg.pcs)*int(unsafe.Sizeof(uintptr(0)))
+	for _, kv := range msg.Data {
+		n += int(unsafe.Sizeof(kv)) + len(kv.Key)
+		if s, ok := kv.Value.(string); ok {
+			n += len(s)
+		}
+	}
+	return n
+}
+
+// reserveQueue reserves room in the listener queue for msg. It reports
+// whether msg can be queued.
+func reserveQueue(msg *Message) bool {
+	max := atomic.LoadInt64(&queueMaxBytes)
+	if max <= 0 {
+		return true
+	}
+
+	msg.size = messageMemory(msg)
+	if atomic.AddInt64(&queueBytes, int64(msg.size)) > max {
+		if msg.Type == TypeAudit {
+			return true
+		}
+		atomic.AddInt64(&queueBytes, -int64(msg.size))
+		atomic.AddInt64(&queueDropped, 1)
+		return false
+	}
+
+	if n := atomic.SwapInt64(&queueDropped, 0); n > 0 {
+		Warning("listener queue memory exceeded", "dropped", n)
+	}
+	return true
+}
+
+// releaseQueue releases the room reserved for a message of the given size in
+// the listener queue.
+func releaseQueue(size int) {
+	if size > 0 {
+		atomic.AddInt64(&queueBytes, -int64(size))
+	}
+}
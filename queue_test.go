@@ -0,0 +1,42 @@
+package say
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSetQueueMemory(t *testing.T) {
+	big := strings.Repeat("x", 1000)
+	block := make(chan struct{})
+	var got []string
+	SetListener(func(m *Message) {
+		<-block
+		if m.Type == TypeAudit {
+			got = append(got, "AUDIT")
+			return
+		}
+		s := m.Content[:1]
+		if n, ok := m.Data.Get("dropped"); ok {
+			s += fmt.Sprint(n)
+		}
+		got = append(got, s)
+	})
+	SetQueueMemory(2500)
+	defer SetQueueMemory(0)
+
+	for i := 0; i < 4; i++ {
+		Info(big)
+	}
+	Audit("foo")
+	close(block)
+	Flush()
+	Info("y")
+	Flush()
+	SetListener(nil)
+
+	want := []string{"x", "x", "AUDIT", "l2", "y"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,45 @@
+package say
+
+import (
+	"math/rand"
+	"sync"
+)
+
+var (
+	randMu sync.Mutex
+	rnd    *rand.Rand
+)
+
+// SetRand sets the random number generator used by Say, e.g. to sample
+// messages. Along with SetClock, a generator with a fixed seed makes the
+// messages sent by a program reproducible:
+//
+//	say.SetRand(rand.New(rand.NewSource(42)))
+//
+// The generator is only used by Say under a lock. SetRand(nil) restores the
+// default generator of the math/rand package.
+func SetRand(r *rand.Rand) {
+	randMu.Lock()
+	rnd = r
+	randMu.Unlock()
+}
+
+// randFloat64 returns a random number in [0.0,1.0).
+func randFloat64() float64 {
+	randMu.Lock()
+	defer randMu.Unlock()
+	if rnd == nil {
+		return rand.Float64()
+	}
+	return rnd.Float64()
+}
+
+// randUint64 returns a random 64-bit number.
+func randUint64() uint64 {
+	randMu.Lock()
+	defer randMu.Unlock()
+	if rnd == nil {
+		return rand.Uint64()
+	}
+	return rnd.Uint64()
+}
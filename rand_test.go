@@ -0,0 +1,32 @@
+package say
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSetRand(t *testing.T) {
+	defer SetRand(nil)
+
+	sample := func() []byte {
+		SetRand(rand.New(rand.NewSource(42)))
+		var kept []byte
+		SetListener(func(m *Message) {
+			if m.Type == TypeInfo {
+				kept = append(kept, m.Content[0])
+			}
+		})
+		log := NewLogger(Sampling(0.5))
+		for c := byte('a'); c <= 'z'; c++ {
+			log.Info(string(c))
+		}
+		Flush()
+		SetListener(nil)
+		return kept
+	}
+
+	a, b := sample(), sample()
+	if string(a) != string(b) {
+		t.Errorf("sampling with the same seed kept %q then %q", a, b)
+	}
+}
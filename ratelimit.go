@@ -0,0 +1,130 @@
+package say
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type rateLimit struct {
+	max     int
+	count   int
+	dropped int
+	logger  *Logger // The Logger of the last dropped message.
+	stop    func()  // Stops the periodic report.
+}
+
+var (
+	rateMu     sync.Mutex
+	rateLimits = make(map[string]*rateLimit)
+	numLimits  int32 // Accessed atomically.
+)
+
+// RateLimit limits the messages with the given key to max per interval, so
+// that a hot path cannot flood the output. The messages over the limit are
+// dropped and, at the end of each interval, a WARN message tells how many
+// were:
+//
+//	WARN  message repeated 42 times	| message="connection refused"
+//
+// The intervals are measured by the clock set with SetClock, in a goroutine.
+// Flush also prints the pending counts.
+//
+// The key of a metric is its name and the key of a USER message is its code.
+// The key of the other messages is their text, without the stack trace.
+//
+// A max or an interval of 0 removes the limit.
+func RateLimit(key string, max int, interval time.Duration) {
+	rateMu.Lock()
+	old := rateLimits[key]
+	if max <= 0 || interval <= 0 {
+		delete(rateLimits, key)
+	} else {
+		r := &rateLimit{max: max}
+		r.stop = every(interval, func() { reportRateLimit(key, r, true) })
+		rateLimits[key] = r
+	}
+	atomic.StoreInt32(&numLimits, int32(len(rateLimits)))
+	rateMu.Unlock()
+
+	if old != nil {
+		old.stop()
+		reportRateLimit(key, old, true)
+	}
+}
+
+// hasRateLimits reports whether a rate limit is set. It allows skipping the
+// rate limiting without converting the key of a message to a string.
+func hasRateLimits() bool {
+	return atomic.LoadInt32(&numLimits) > 0
+}
+
+// withinRateLimit reports whether a message with the given key can be sent
+// without exceeding its rate limit.
+func (l *Logger) withinRateLimit(key string) bool {
+	if !hasRateLimits() {
+		return true
+	}
+
+	rateMu.Lock()
+	r, ok := rateLimits[key]
+	if !ok {
+		rateMu.Unlock()
+		return true
+	}
+
+	within := r.count < r.max
+	if within {
+		r.count++
+	} else {
+		r.dropped++
+		r.logger = l
+	}
+	rateMu.Unlock()
+	return within
+}
+
+// reportRateLimit prints how many messages with the given key have been
+// dropped since the last report, if any. If reset is true, a new interval
+// starts.
+func reportRateLimit(key string, r *rateLimit, reset bool) {
+	rateMu.Lock()
+	dropped, l := r.dropped, r.logger
+	r.dropped, r.logger = 0, nil
+	if reset {
+		r.count = 0
+	}
+	rateMu.Unlock()
+
+	if dropped > 0 {
+		buf := getBuffer()
+		buf.appendString("message repeated ")
+		buf.appendInt(int64(dropped))
+		buf.appendString(" times")
+		l.send(TypeWarning, buf.String(), []interface{}{"message", key})
+	}
+}
+
+// flushRateLimits prints the pending counts of dropped messages, sorted by key.
+func flushRateLimits() {
+	if !hasRateLimits() {
+		return
+	}
+
+	rateMu.Lock()
+	keys := make([]string, 0, len(rateLimits))
+	for k := range rateLimits {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	limits := make([]*rateLimit, len(keys))
+	for i, k := range keys {
+		limits[i] = rateLimits[k]
+	}
+	rateMu.Unlock()
+
+	for i, r := range limits {
+		reportRateLimit(keys[i], r, false)
+	}
+}
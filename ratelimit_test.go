@@ -0,0 +1,81 @@
+package say
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gopkg.in/say.v0/sayclock"
+)
+
+func TestRateLimit(t *testing.T) {
+	RateLimit("connection refused", 2, time.Hour)
+	defer RateLimit("connection refused", 0, 0)
+	RateLimit("foo", 1, time.Hour)
+	defer RateLimit("foo", 0, 0)
+
+	expect(t, func() {
+		err := errors.New("connection refused")
+		for i := 0; i < 5; i++ {
+			Error(err)
+			Event("foo")
+			Info("bar")
+		}
+		Flush()
+		Error(err)
+		Flush()
+	}, []string{
+		`ERROR connection refused`,
+		`EVENT foo`,
+		`INFO  bar`,
+		`ERROR connection refused`,
+		`INFO  bar`,
+		`INFO  bar`,
+		`INFO  bar`,
+		`INFO  bar`,
+		`WARN  message repeated 3 times	| message="connection refused"`,
+		`WARN  message repeated 4 times	| message="foo"`,
+		`WARN  message repeated 1 times	| message="connection refused"`,
+	})
+}
+
+func TestRateLimitInterval(t *testing.T) {
+	c := sayclock.NewFake(time.Date(2015, 9, 1, 21, 37, 0, 0, time.UTC))
+	SetClock(c)
+	defer SetClock(sayclock.Real)
+
+	msgs := make(chan string, 10)
+	SetListener(func(m *Message) {
+		buf := getBuffer()
+		buf.appendMessage(m)
+		msgs <- buf.String()
+	})
+	defer SetListener(nil)
+	RateLimit("connection refused", 1, time.Minute)
+	defer RateLimit("connection refused", 0, 0)
+
+	receive := func(want string) {
+		t.Helper()
+		select {
+		case got := <-msgs:
+			if got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no message received, want %q", want)
+		}
+	}
+
+	// The flood stops: the count is reported at the end of the interval.
+	err := errors.New("connection refused")
+	for i := 0; i < 3; i++ {
+		Error(err)
+	}
+	receive("ERROR connection refused\n")
+	c.Add(time.Minute)
+	receive("WARN  message repeated 2 times\t| message=\"connection refused\"\n")
+
+	// A new interval has started.
+	Error(err)
+	receive("ERROR connection refused\n")
+}
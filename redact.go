@@ -0,0 +1,165 @@
+package say
+
+import "regexp"
+
+// A Redactor removes sensitive information, such as personal data, from the
+// messages before they are printed or sent to the listener.
+type Redactor interface {
+	// RedactContent returns the content of a message without sensitive
+	// information.
+	RedactContent(content string) string
+	// RedactData returns the value of a data pair without sensitive
+	// information.
+	RedactData(key string, value interface{}) interface{}
+}
+
+var redactor Redactor
+
+// SetRedactor sets the Redactor applied to the content, the stack trace and
+// the data of all messages, including the values returned by Hooks.
+// SetRedactor(nil) removes it, which is the default.
+//
+// The keys of metrics are not redacted.
+func SetRedactor(r Redactor) {
+	mu.Lock()
+	redactor = r
+	mu.Unlock()
+}
+
+// Redacted is the value replacing the redacted data values.
+const Redacted = "[REDACTED]"
+
+// redact applies r to the content, the stack trace and the data of msg. A
+// stack trace that is not rendered yet is redacted when it is rendered.
+func redact(r Redactor, msg *Message) {
+	if msg.Content != "" {
+		msg.Content = r.RedactContent(msg.Content)
+	}
+	if msg.stack != "" {
+		msg.stack = r.RedactContent(msg.stack)
+	}
+	if len(msg.pcs) > 0 {
+		msg.redactor = r
+	}
+	redactData(r, msg.Data, "")
+}
+
+// redactData applies r to the values of data. The keys of the pairs of a group
+// are prefixed with the name of the group. Since the Data of a group may be
+// shared, it is copied before being redacted.
+func redactData(r Redactor, data Data, prefix string) {
+	for i, kv := range data {
+		if g, ok := kv.Value.(Data); ok {
+			g = append(Data(nil), g...)
+			redactData(r, g, prefix+kv.Key+".")
+			data[i].Value = g
+			continue
+		}
+		if h, ok := kv.Value.(Hook); ok {
+			data[i].Value = redactHook(r, prefix+kv.Key, h)
+			continue
+		}
+		data[i].Value = r.RedactData(prefix+kv.Key, kv.Value)
+	}
+}
+
+// redactHook returns a Hook applying r to the value of h, since Hooks are
+// only called when the message is printed.
+func redactHook(r Redactor, key string, h Hook) Hook {
+	return Hook(func() interface{} {
+		v := h()
+		if v == nil {
+			return nil
+		}
+		return r.RedactData(key, filterDataValue(key, v))
+	})
+}
+
+type keyRedactor struct {
+	keys  map[string]bool
+	allow bool
+}
+
+// DenyKeys returns a Redactor that replaces the values of the given keys by
+// Redacted. The keys of the pairs of a group are prefixed with the name of the
+// group and a dot, e.g. "http.authorization".
+func DenyKeys(keys ...string) Redactor {
+	return keyRedactor{keys: keySet(keys)}
+}
+
+// AllowOnlyKeys returns a Redactor that replaces the values of all the keys
+// but the given ones by Redacted. The keys of the pairs of a group are
+// prefixed with the name of the group and a dot, e.g. "http.status".
+func AllowOnlyKeys(keys ...string) Redactor {
+	return keyRedactor{keys: keySet(keys), allow: true}
+}
+
+func keySet(keys []string) map[string]bool {
+	m := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		m[k] = true
+	}
+	return m
+}
+
+func (r keyRedactor) RedactContent(content string) string {
+	return content
+}
+
+func (r keyRedactor) RedactData(key string, value interface{}) interface{} {
+	if r.keys[key] != r.allow {
+		return Redacted
+	}
+	return value
+}
+
+// Patterns matching common personal data, to be used with RedactPatterns.
+var (
+	EmailPattern      = regexp.MustCompile(`[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}`)
+	CreditCardPattern = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+)
+
+type patternRedactor []*regexp.Regexp
+
+// RedactPatterns returns a Redactor that replaces the parts of the contents
+// and of the string data values matching one of the patterns by Redacted:
+//
+//	say.SetRedactor(say.RedactPatterns(say.EmailPattern, say.CreditCardPattern))
+func RedactPatterns(patterns ...*regexp.Regexp) Redactor {
+	return patternRedactor(patterns)
+}
+
+func (r patternRedactor) RedactContent(content string) string {
+	for _, re := range r {
+		content = re.ReplaceAllLiteralString(content, Redacted)
+	}
+	return content
+}
+
+func (r patternRedactor) RedactData(key string, value interface{}) interface{} {
+	if s, ok := value.(string); ok {
+		return r.RedactContent(s)
+	}
+	return value
+}
+
+type multiRedactor []Redactor
+
+// Redactors returns a Redactor applying all the given Redactors in order.
+func Redactors(rs ...Redactor) Redactor {
+	return multiRedactor(rs)
+}
+
+func (m multiRedactor) RedactContent(content string) string {
+	for _, r := range m {
+		content = r.RedactContent(content)
+	}
+	return content
+}
+
+func (m multiRedactor) RedactData(key string, value interface{}) interface{} {
+	for _, r := range m {
+		value = r.RedactData(key, value)
+	}
+	return value
+}
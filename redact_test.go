@@ -0,0 +1,73 @@
+package say
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestSetRedactor(t *testing.T) {
+	defer SetRedactor(nil)
+
+	SetRedactor(Redactors(
+		DenyKeys("password", "http.authorization"),
+		RedactPatterns(EmailPattern, CreditCardPattern),
+	))
+	expect(t, func() {
+		log := NewLogger()
+		log.SetData("password", "secret")
+		log.Info("Signed up bob@example.com", "card", "4111 1111 1111 1111", "n", 4111111111111111)
+		log.Warning("foo", Group("http", "authorization", "Bearer x", "status", 401))
+		log.Event("signup", "email", "alice@example.org")
+	}, []string{
+		`INFO  Signed up [REDACTED]	| password="[REDACTED]" card="[REDACTED]" n=4111111111111111`,
+		`WARN  foo	| password="[REDACTED]" http.authorization="[REDACTED]" http.status=401`,
+		`EVENT signup	| password="[REDACTED]" email="[REDACTED]"`,
+	})
+
+	SetRedactor(AllowOnlyKeys("status"))
+	expect(t, func() {
+		g := Group("http", "status", 200)
+		Info("foo", "status", 200, "user", "bob", g)
+		Info("foo", g)
+	}, []string{
+		`INFO  foo	| status=200 user="[REDACTED]" http.status="[REDACTED]"`,
+		`INFO  foo	| http.status="[REDACTED]"`,
+	})
+}
+
+func TestRedactHookAndStackTrace(t *testing.T) {
+	defer SetRedactor(nil)
+	SetRedactor(Redactors(DenyKeys("token"), RedactPatterns(EmailPattern)))
+
+	expect(t, func() {
+		email := Hook(func() interface{} { return "bob@example.com" })
+		token := Hook(func() interface{} { return "xyz" })
+		none := Hook(func() interface{} { return nil })
+		Info("foo", "email", email, "token", token, "none", none)
+		Info("foo", Group("user", "email", email))
+	}, []string{
+		`INFO  foo	| email="[REDACTED]" token="[REDACTED]"`,
+		`INFO  foo	| user.email="[REDACTED]"`,
+	})
+
+	SetRedactor(RedactPatterns(regexp.MustCompile(`redactedStackTraceHelper`)))
+	DisableStackTraces(false)
+	defer DisableStackTraces(true)
+	for _, deferred := range []bool{false, true} {
+		DeferStackTraces(deferred)
+		var st string
+		SetListener(func(m *Message) { st = m.StackTrace() })
+		redactedStackTraceHelper()
+		Flush()
+		SetListener(nil)
+		if st == "" || strings.Contains(st, "redactedStackTraceHelper") {
+			t.Errorf("stack trace not redacted with DeferStackTraces(%v):\n%s", deferred, st)
+		}
+	}
+	DeferStackTraces(false)
+}
+
+func redactedStackTraceHelper() {
+	Error("oops")
+}
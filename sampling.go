@@ -0,0 +1,105 @@
+package say
+
+import (
+	"strconv"
+	"sync/atomic"
+)
+
+// Sampling makes the Logger send only a fraction rate of its DEBUG and INFO
+// messages, chosen randomly. Use it to keep the volume of logs under control
+// under high traffic. The other types of messages are always sent.
+//
+// The sent messages get a sampled=true data pair and an EVENT
+// say.sampled_out counts the messages that were not sent, so that listeners
+// can re-scale what they compute from the sampled messages.
+//
+// A rate of 1 or more disables the sampling. The Loggers created from this
+// Logger share its sampling.
+func Sampling(rate float64) Option {
+	return Option(func(l *Logger) {
+		if rate >= 1 {
+			l.sampler = nil
+			return
+		}
+		l.sampler = &sampler{rate: rate}
+	})
+}
+
+type sampler struct {
+	rate    float64
+	dropped int64 // Accessed atomically.
+}
+
+// isSampled reports whether a message of the given type is subject to
+// sampling.
+func isSampled(typ Type) bool {
+	return typ == TypeDebug || typ == TypeInfo
+}
+
+// withinSampling reports whether a message of the given type sent with this
+// Logger is kept by the sampling.
+func (l *Logger) withinSampling(typ Type) bool {
+	s := l.sampler
+	if s == nil || !isSampled(typ) {
+		return true
+	}
+
+	if randFloat64() >= s.rate {
+		atomic.AddInt64(&s.dropped, 1)
+		return false
+	}
+
+	if n := atomic.SwapInt64(&s.dropped, 0); n > 0 {
+		l.sendValue(TypeEvent, "say.sampled_out", float64(n), "", []interface{}{"rate", s.rate})
+	}
+	return true
+}
+
+// A SampleRate is the sample rate of a metric, created with Sampled.
+type SampleRate float64
+
+// Sampled sets the sample rate of a metric. It is passed in place of a
+// key-value pair to Event, Events, Value, ValueUnit and Timing.Say:
+//
+//	say.Event("cache.hit", say.Sampled(0.1))
+//	// Output (one time out of ten):
+//	EVENT cache.hit	| sample_rate=0.1
+//
+// The metric is only sent with the probability rate so that hot paths can be
+// down-sampled. It carries a sample_rate data pair so that listeners can
+// re-scale the values, e.g. by forwarding the rate to StatsD. A rate of 1 or
+// more sends every metric without the data pair.
+func Sampled(rate float64) SampleRate {
+	return SampleRate(rate)
+}
+
+// withinSampleRate reports whether a metric sent with the given data is kept
+// by its sample rate.
+func withinSampleRate(data []interface{}) bool {
+	for _, v := range data {
+		if r, ok := v.(SampleRate); ok && r < 1 {
+			return randFloat64() < float64(r)
+		}
+	}
+	return true
+}
+
+// SampleRate returns the sample rate of a metric sent with Sampled. It returns
+// 1 if the metric is not sampled.
+func (m *Message) SampleRate() float64 {
+	v, ok := m.Data.Get("sample_rate")
+	if !ok {
+		return 1
+	}
+	switch t := v.(type) {
+	case float64:
+		return t
+	case int:
+		return float64(t)
+	case string:
+		if f, err := strconv.ParseFloat(t, 64); err == nil {
+			return f
+		}
+	}
+	return 1
+}
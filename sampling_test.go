@@ -0,0 +1,82 @@
+package say
+
+import "testing"
+
+func TestSampling(t *testing.T) {
+	const n = 1000
+	var kept, sampledOut int
+	SetListener(func(m *Message) {
+		switch m.Type {
+		case TypeInfo:
+			if v, ok := m.Data.Get("sampled"); !ok || v != true {
+				t.Errorf("sampled = %v, want true", v)
+			}
+			kept++
+		case TypeEvent:
+			if m.Key != "say.sampled_out" {
+				t.Errorf("unexpected EVENT %q", m.Key)
+			}
+			sampledOut += int(m.Value)
+		}
+	})
+	log := NewLogger(Sampling(0.5))
+	for i := 0; i < n; i++ {
+		log.Info("foo")
+	}
+	Flush()
+	SetListener(nil)
+
+	pending := int(log.sampler.dropped)
+	if kept+sampledOut+pending != n {
+		t.Errorf("kept %d + sampled out %d + pending %d, want %d", kept, sampledOut, pending, n)
+	}
+	if kept < n/4 || kept > 3*n/4 {
+		t.Errorf("kept %d messages out of %d with a rate of 0.5", kept, n)
+	}
+
+	expect(t, func() {
+		log := NewLogger(Sampling(0))
+		log.Info("foo")
+		log.Warning("bar")
+		log.NewLogger(Sampling(1)).Info("baz")
+	}, []string{
+		"WARN  bar",
+		"INFO  baz",
+	})
+}
+
+func TestSampled(t *testing.T) {
+	const n = 1000
+	var sent int
+	SetListener(func(m *Message) {
+		if r := m.SampleRate(); r != 0.5 {
+			t.Errorf("SampleRate() = %v, want 0.5", r)
+		}
+		sent++
+	})
+	for i := 0; i < n; i++ {
+		Event("foo", Sampled(0.5))
+	}
+	Flush()
+	SetListener(nil)
+	if sent < n/4 || sent > 3*n/4 {
+		t.Errorf("sent %d metrics out of %d with a rate of 0.5", sent, n)
+	}
+
+	expect(t, func() {
+		Event("foo", Sampled(0))
+		Value("bar", 5, Sampled(1), "a", 1)
+		NewTiming().Say("baz", "a", 1, Sampled(1e-9))
+	}, []string{
+		"VALUE bar:5	| a=1",
+	})
+
+	m := Message{Type: TypeEvent, Key: "foo", Value: 1}
+	if r := m.SampleRate(); r != 1 {
+		t.Errorf("SampleRate() = %v without a sample rate, want 1", r)
+	}
+	m.Data = Data{{"sample_rate", "0.25"}}
+	if r := m.SampleRate(); r != 0.25 {
+		t.Errorf("SampleRate() = %v, want 0.25", r)
+	}
+}
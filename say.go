@@ -1,12 +1,22 @@
 package say
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"errors"
+	"io"
 	"log"
 	"runtime"
+	"runtime/trace"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode"
+
+	"gopkg.in/say.v0/sayclock"
 )
 
 var (
@@ -15,23 +25,46 @@ var (
 	errOddNumArgs   = errors.New("say: odd number of data arguments")
 	errKeyNotString = errors.New("say: keys must be string")
 	errKeyEmpty     = errors.New("say: key is empty")
-	errKeyInvalid   = errors.New("say: keys must not contain ':', '=', tabs or newlines")
+	errKeyInvalid   = errors.New("say: keys must not contain tabs or newlines")
+	errWriterType   = errors.New("say: Writer only accepts log message types")
+	errNotNumber    = errors.New("say: ValueUnit only accepts numbers")
+	errUnitInvalid  = errors.New("say: units must only contain letters, '%' or '/'")
 )
 
 // Logger is the object that prints messages.
 type Logger struct {
 	skipStackFrames int
 	data            Data
+	disabledTypes   uint32 // Accessed atomically.
+	jsonData        bool
+	tenant          string
+	out             io.Writer
+	name            string
+	sampler         *sampler
+	caller          bool
+	callerSkip      int
+	ttl             time.Duration
 }
 
-// NewLogger creates a new Logger that inherits the Data and SkipStackFrames
-// values from the parent Logger.
+// NewLogger creates a new Logger that inherits the Data, the disabled types
+// and the options from the parent Logger.
 func (l *Logger) NewLogger(opts ...Option) *Logger {
 	log := new(Logger)
 	mu.RLock()
 	log.skipStackFrames = l.skipStackFrames
-	log.data = l.data
+	// Limit the capacity so that appending to the child's data does not
+	// overwrite the data of its siblings.
+	log.data = l.data[:len(l.data):len(l.data)]
+	log.jsonData = l.jsonData
+	log.tenant = l.tenant
+	log.out = l.out
+	log.name = l.name
+	log.sampler = l.sampler
+	log.caller = l.caller
+	log.callerSkip = l.callerSkip
+	log.ttl = l.ttl
 	mu.RUnlock()
+	log.disabledTypes = atomic.LoadUint32(&l.disabledTypes)
 
 	for _, o := range opts {
 		o(log)
@@ -40,8 +73,8 @@ func (l *Logger) NewLogger(opts ...Option) *Logger {
 	return log
 }
 
-// NewLogger creates a new Logger inherits the Data and SkipStackFrames
-// values from the package-level Logger.
+// NewLogger creates a new Logger that inherits the Data, the disabled types
+// and the options from the package-level Logger.
 func NewLogger(opts ...Option) *Logger {
 	return defaultLogger.NewLogger(opts...)
 }
@@ -59,6 +92,93 @@ func SkipStackFrames(skip int) Option {
 	})
 }
 
+// JSONData sets whether the Data of the messages is printed as a single JSON
+// object instead of key=value pairs:
+//
+//	INFO  hello	| {"id":5,"foo":"bar"}
+//
+// It is false by default.
+func JSONData(b bool) Option {
+	return Option(func(l *Logger) {
+		l.jsonData = b
+	})
+}
+
+// WithOutput makes the Logger print its messages to w instead of the package
+// output (see Redirect).
+//
+// The messages of such a Logger are always printed to w, even when a listener
+// is set: they are not sent to the listener.
+func WithOutput(w io.Writer) Option {
+	return Option(func(l *Logger) {
+		l.out = w
+	})
+}
+
+// Name names the Logger after a component of the application (e.g.
+// "http.server"). The keys of the metrics sent with the Logger are prefixed
+// with the name and the messages get a logger=name data pair:
+//
+//	EVENT http.server.request	| logger="http.server"
+//
+// The name of a child Logger is appended to the name of its parent, separated
+// with a dot: NewLogger(Name("tls")) on the Logger above is named
+// "http.server.tls".
+//
+// The name is checked and normalized like a metric key, according to the key
+// policy. Name panics if name is empty or contains tabs or newlines.
+func Name(name string) Option {
+	name, err := checkKey(name)
+	if err != nil {
+		panic(err)
+	}
+	return Option(func(l *Logger) {
+		n := name
+		if l.name != "" {
+			n = l.name + "." + name
+		}
+		l.name = n
+	})
+}
+
+// WithCaller adds the file and line of the caller to the messages of the
+// Logger:
+//
+//	INFO  hello	| caller="server/main.go:42"
+//
+// The caller is the first function outside of Say. Use CallerSkip when the
+// Logger is wrapped by helper functions.
+func WithCaller() Option {
+	return Option(func(l *Logger) {
+		l.caller = true
+	})
+}
+
+// CallerSkip sets the number of frames to skip, after the frames of Say, to
+// find the caller added by WithCaller. For example, it should be 1 for a Logger
+// only used through a logging helper function. It is 0 by default.
+func CallerSkip(skip int) Option {
+	return Option(func(l *Logger) {
+		l.callerSkip = skip
+	})
+}
+
+// TTL sets how long the messages of the Logger remain valid, e.g. how long
+// the value of a GAUGE can be considered current. Listeners can use it to mark
+// a series as stale instead of holding its last value forever.
+//
+// The TTL is set in the TTL field of the messages and printed in milliseconds
+// as a ttl_ms data pair:
+//
+//	GAUGE queue.size:5	| ttl_ms=60000
+//
+// A TTL of 0 means that the messages do not expire, which is the default.
+func TTL(d time.Duration) Option {
+	return Option(func(l *Logger) {
+		l.ttl = d
+	})
+}
+
 // DisableStackTraces disables printing the stack traces by default. This can
 // still be
 func DisableStackTraces(b bool) {
@@ -71,27 +191,62 @@ func DisableStackTraces(b bool) {
 	mu.Unlock()
 }
 
+var disabledTypes uint32 // Accessed atomically.
+
+// DisableType disables all the messages of the given type, for all Loggers.
+// Use it to turn off entire categories of messages (e.g. all metrics) at the
+// source.
+func DisableType(typ Type) {
+	setTypeDisabled(&disabledTypes, typ, true)
+}
+
+// EnableType re-enables the messages of the given type disabled with
+// DisableType.
+func EnableType(typ Type) {
+	setTypeDisabled(&disabledTypes, typ, false)
+}
+
+// DisableType disables the messages of the given type sent with this Logger
+// and the Loggers created from it afterwards.
+func (l *Logger) DisableType(typ Type) {
+	setTypeDisabled(&l.disabledTypes, typ, true)
+}
+
+// EnableType re-enables the messages of the given type disabled with
+// Logger.DisableType.
+func (l *Logger) EnableType(typ Type) {
+	setTypeDisabled(&l.disabledTypes, typ, false)
+}
+
+func setTypeDisabled(mask *uint32, typ Type, disabled bool) {
+	for {
+		old := atomic.LoadUint32(mask)
+		m := old | typeBit(typ)
+		if !disabled {
+			m = old &^ typeBit(typ)
+		}
+		if atomic.CompareAndSwapUint32(mask, old, m) {
+			return
+		}
+	}
+}
+
+// isEnabled reports whether the messages of the given type are enabled for
+// this Logger.
+func (l *Logger) isEnabled(typ Type) bool {
+	mask := atomic.LoadUint32(&disabledTypes) | atomic.LoadUint32(&l.disabledTypes)
+	return mask&typeBit(typ) == 0
+}
+
 // Event prints an EVENT message. Use it to track the occurence of a particular
 // event (e.g. a user signs up, a database query fails).
 func (l *Logger) Event(name string, data ...interface{}) {
-	if err := isKeyValid(name); err != nil {
+	name, err := checkKey(name)
+	if err != nil {
 		l.sendError(err, 1)
 		return
 	}
-	l.send(TypeEvent, name, data)
-}
-
-func isKeyValid(key string) error {
-	if key == "" {
-		return errKeyEmpty
-	}
-	for i := 0; i < len(key); i++ {
-		switch key[i] {
-		case ':', '=', '\t', '\n':
-			return errKeyInvalid
-		}
-	}
-	return nil
+	l.sendValue(TypeEvent, name, 1, "", data)
 }
 
 // Event prints an EVENT message. Use it to track the occurence of a particular
@@ -103,16 +258,13 @@ func Event(name string, data ...interface{}) {
 // Events prints an EVENT message with an increment value. Use it to track the
 // occurence of a batch of events (e.g. how many new files were uploaded).
 func (l *Logger) Events(name string, incr int, data ...interface{}) {
-	if err := isKeyValid(name); err != nil {
+	name, err := checkKey(name)
+	if err != nil {
 		l.sendError(err, 1)
 		return
 	}
 
-	buf := getBuffer()
-	buf.appendString(name)
-	buf.appendByte(':')
-	buf.appendInt(int64(incr))
-	l.send(TypeEvent, buf.String(), data)
+	l.sendValue(TypeEvent, name, float64(incr), "", data)
 }
 
 // Events prints an EVENT message with an increment value. Use it to track the
@@ -133,15 +285,54 @@ func Value(name string, value interface{}, data ...interface{}) {
 	defaultLogger.Value(name, value, data...)
 }
 
+// ValueUnit prints a VALUE message with a numeric value and its unit, e.g.
+// ValueUnit("payload_size", 1234, "B") prints "VALUE payload_size:1234B". The
+// unit must only contain letters, '%' or '/'. Use Message.ValueUnit to get the
+// value and the unit separately.
+func (l *Logger) ValueUnit(name string, value interface{}, unit string, data ...interface{}) {
+	name, err := checkKey(name)
+	if err != nil {
+		l.sendError(err, 1)
+		return
+	}
+	if !isValidUnit(unit) {
+		l.sendError(errUnitInvalid, 1)
+		return
+	}
+	f, ok := toNumber(value)
+	if !ok {
+		l.sendError(errNotNumber, 1)
+		return
+	}
+	l.sendValue(TypeValue, name, f, unit, data)
+}
+
+// ValueUnit prints a VALUE message with a numeric value and its unit, e.g.
+// ValueUnit("payload_size", 1234, "B") prints "VALUE payload_size:1234B". The
+// unit must only contain letters, '%' or '/'.
+func ValueUnit(name string, value interface{}, unit string, data ...interface{}) {
+	defaultLogger.ValueUnit(name, value, unit, data...)
+}
+
+func isValidUnit(unit string) bool {
+	for _, c := range unit {
+		if !unicode.IsLetter(c) && c != '%' && c != '/' {
+			return false
+		}
+	}
+	return true
+}
+
 // A Timing helps printing a duration.
 type Timing struct {
 	l     *Logger
 	start time.Time
+	mono  time.Time // Start time on the monotonic clock of the system.
 }
 
 // NewTiming returns a new Timing with the same associated data than the Logger.
 func (l *Logger) NewTiming() Timing {
-	return Timing{l: l, start: now()}
+	return Timing{l: l, start: now(), mono: time.Now()}
 }
 
 // NewTiming returns a new Timing with the package-level data.
@@ -154,28 +345,58 @@ func NewTiming() Timing {
 // duration, webservice call duration).
 func (t Timing) Say(name string, data ...interface{}) {
 	n := int64(t.Get() / time.Millisecond)
-	if err := isKeyValid(name); err != nil {
+	name, err := checkKey(name)
+	if err != nil {
 		t.l.sendError(err, 1)
 		return
 	}
 
-	buf := getBuffer()
-	buf.appendString(name)
-	buf.appendByte(':')
-	buf.appendInt(n)
-	buf.appendString("ms")
-	t.l.send(TypeValue, buf.String(), data)
+	t.l.sendValue(TypeValue, name, float64(n), "ms", data)
 }
 
-// Get returns the duration since the Timing has been created.
+// Get returns the duration since the Timing has been created, as measured by
+// the clock set with SetClock. It is never negative, even if the clock goes
+// backwards.
+//
+// With the default clock, durations are measured with the monotonic clock of
+// the system and are not affected by changes of the wall clock.
 func (t Timing) Get() time.Duration {
-	return now().Sub(t.start)
+	d := now().Sub(t.start)
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// GetMonotonic returns the duration since the Timing has been created, as
+// measured by the monotonic clock of the system whatever the clock set with
+// SetClock.
+func (t Timing) GetMonotonic() time.Duration {
+	return time.Since(t.mono)
+}
+
+var traceRegions bool
+
+// TraceRegions sets whether Time and Job annotate the execution traces of the
+// runtime/trace package: when tracing is enabled, Time runs f in a region and
+// Job runs the job in a task, both named after the metric. This way the
+// output of go tool trace uses the same names as the metrics. It is off by
+// default.
+//
+// This function must not be called concurrently with the other functions of
+// this package.
+func TraceRegions(b bool) {
+	traceRegions = b
 }
 
 // Time prints a VALUE message with the duration in milliseconds of running f.
 func (l *Logger) Time(name string, f func(), data ...interface{}) {
 	t := l.NewTiming()
-	f()
+	if traceRegions && trace.IsEnabled() {
+		trace.WithRegion(context.Background(), name, f)
+	} else {
+		f()
+	}
 	t.Say(name, data...)
 }
 
@@ -184,6 +405,46 @@ func Time(name string, f func(), data ...interface{}) {
 	defaultLogger.Time(name, f, data...)
 }
 
+// Histogram prints a HISTO message. Use it for values whose distribution
+// matters, such as the sizes of requests, so that listeners can aggregate them
+// as histograms instead of as VALUE messages.
+func (l *Logger) Histogram(name string, value interface{}, data ...interface{}) {
+	l.keyValue(TypeHisto, name, value, data)
+}
+
+// Histogram prints a HISTO message. Use it for values whose distribution
+// matters, such as the sizes of requests, so that listeners can aggregate them
+// as histograms instead of as VALUE messages.
+func Histogram(name string, value interface{}, data ...interface{}) {
+	defaultLogger.Histogram(name, value, data...)
+}
+
+// Unique prints a UNIQ message. Use it to count the number of distinct IDs
+// seen, such as the daily active users, which listeners can report as StatsD
+// sets:
+//
+//	say.Unique("active_users", userID)
+//	// Output:
+//	UNIQ  active_users:42
+func (l *Logger) Unique(name string, id interface{}, data ...interface{}) {
+	name, err := checkKey(name)
+	if err != nil {
+		l.sendError(err, 1)
+		return
+	}
+
+	buf := getBuffer()
+	buf.appendValue(id)
+	l.sendText(TypeUnique, name, buf.String(), data)
+}
+
+// Unique prints a UNIQ message. Use it to count the number of distinct IDs
+// seen, such as the daily active users, which listeners can report as StatsD
+// sets.
+func Unique(name string, id interface{}, data ...interface{}) {
+	defaultLogger.Unique(name, id, data...)
+}
+
 // Gauge prints a GAUGE message. Use it to capture the current value of
 // something that changes over time (e.g. number of active goroutines, number of
 // connected users)
@@ -198,47 +459,163 @@ func Gauge(name string, value interface{}, data ...interface{}) {
 	defaultLogger.Gauge(name, value, data...)
 }
 
+// GaugeAdd prints a GAUGE message with a value relative to the current value
+// of the gauge, following the StatsD convention for gauge deltas:
+//
+//	say.GaugeAdd("conns", 2)
+//	// Output:
+//	GAUGE conns:+2
+func (l *Logger) GaugeAdd(name string, delta float64, data ...interface{}) {
+	l.gaugeDelta(name, delta, data)
+}
+
+// GaugeAdd prints a GAUGE message with a value relative to the current value
+// of the gauge, following the StatsD convention for gauge deltas.
+func GaugeAdd(name string, delta float64, data ...interface{}) {
+	defaultLogger.gaugeDelta(name, delta, data)
+}
+
+// GaugeSub prints a GAUGE message decrementing the current value of the gauge,
+// following the StatsD convention for gauge deltas:
+//
+//	say.GaugeSub("conns", 1)
+//	// Output:
+//	GAUGE conns:-1
+func (l *Logger) GaugeSub(name string, delta float64, data ...interface{}) {
+	l.gaugeDelta(name, -delta, data)
+}
+
+// GaugeSub prints a GAUGE message decrementing the current value of the gauge,
+// following the StatsD convention for gauge deltas.
+func GaugeSub(name string, delta float64, data ...interface{}) {
+	defaultLogger.gaugeDelta(name, -delta, data)
+}
+
+// gaugeDelta sends a GAUGE whose value is delta with an explicit sign.
+func (l *Logger) gaugeDelta(name string, delta float64, data []interface{}) {
+	name, err := checkKey(name)
+	if err != nil {
+		l.sendError(err, 1)
+		return
+	}
+
+	buf := getBuffer()
+	if delta >= 0 {
+		buf.appendByte('+')
+	}
+	buf.appendNumber(delta)
+	l.sendText(TypeGauge, name, buf.String(), data)
+}
+
 func (l *Logger) keyValue(typ Type, name string, value interface{}, data []interface{}) {
-	if err := isKeyValid(name); err != nil {
+	name, err := checkKey(name)
+	if err != nil {
 		l.sendError(err, 1)
 		return
 	}
 
+	if f, ok := toNumber(value); ok {
+		l.sendValue(typ, name, f, "", data)
+		return
+	}
+
 	buf := getBuffer()
-	buf.appendString(name)
-	buf.appendByte(':')
 	buf.appendValue(value)
-	l.send(typ, buf.String(), data)
+	l.sendText(typ, name, buf.String(), data)
+}
+
+// toNumber converts v to a float64 if v is a number that can be exactly
+// represented as a float64.
+func toNumber(v interface{}) (float64, bool) {
+	switch t := v.(type) {
+	case int:
+		return intToNumber(int64(t))
+	case int64:
+		return intToNumber(t)
+	case int32:
+		return float64(t), true
+	case int16:
+		return float64(t), true
+	case int8:
+		return float64(t), true
+	case uint:
+		return uintToNumber(uint64(t))
+	case uint64:
+		return uintToNumber(t)
+	case uint32:
+		return float64(t), true
+	case uint16:
+		return float64(t), true
+	case uint8:
+		return float64(t), true
+	case float64:
+		return t, true
+	case float32:
+		// Keep the shortest decimal representation of the float32.
+		f, err := strconv.ParseFloat(strconv.FormatFloat(float64(t), 'g', -1, 32), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// maxExactInt is the greatest integer such that all integers in
+// [-maxExactInt, maxExactInt] can be exactly represented as float64.
+const maxExactInt = 1 << 53
+
+func intToNumber(i int64) (float64, bool) {
+	if i > maxExactInt || i < -maxExactInt {
+		return 0, false
+	}
+	return float64(i), true
+}
+
+func uintToNumber(i uint64) (float64, bool) {
+	if i > maxExactInt {
+		return 0, false
+	}
+	return float64(i), true
 }
 
 // Debug prints a DEBUG message only if the debug mode is on.
-func (l *Logger) Debug(msg string, data ...interface{}) {
-	if !debug {
+//
+// The message can be a func() string or a func() interface{} so that an
+// expensive message is only built when it is actually sent:
+//
+//	say.Debug(func() string { return dump(state) })
+func (l *Logger) Debug(msg interface{}, data ...interface{}) {
+	if !DebugEnabled() {
 		return
 	}
 	l.send(TypeDebug, msg, data)
 }
 
 // Debug prints a DEBUG message only if the debug mode is on.
-func Debug(msg string, data ...interface{}) {
+//
+// The message can be a func() string or a func() interface{} so that an
+// expensive message is only built when it is actually sent.
+func Debug(msg interface{}, data ...interface{}) {
 	defaultLogger.Debug(msg, data...)
 }
 
 // Info prints an INFO message.
-func (l *Logger) Info(msg string, data ...interface{}) {
+//
+// The message can be a func() string or a func() interface{} so that an
+// expensive message is only built when it is actually sent.
+func (l *Logger) Info(msg interface{}, data ...interface{}) {
 	l.send(TypeInfo, msg, data)
 }
 
 // Info prints an INFO message.
-func Info(msg string, data ...interface{}) {
+//
+// The message can be a func() string or a func() interface{} so that an
+// expensive message is only built when it is actually sent.
+func Info(msg interface{}, data ...interface{}) {
 	defaultLogger.Info(msg, data...)
 }
 
 // Warning prints a WARNING message.
 func (l *Logger) Warning(v interface{}, data ...interface{}) {
-	buf := getBuffer()
-	buf.appendValue(v)
-	l.send(TypeWarning, buf.String(), data)
+	l.send(TypeWarning, v, data)
 }
 
 // Warning prints a WARNING message.
@@ -247,11 +624,17 @@ func Warning(v interface{}, data ...interface{}) {
 }
 
 // Error prints an ERROR message with the stack trace.
+//
+// If v is an error that captured a stack trace when it was created, such as
+// the errors of github.com/pkg/errors, this stack trace is printed instead.
 func (l *Logger) Error(v interface{}, data ...interface{}) {
 	l.error(TypeError, v, data, 1)
 }
 
 // Error prints an ERROR message with the stack trace.
+//
+// If v is an error that captured a stack trace when it was created, such as
+// the errors of github.com/pkg/errors, this stack trace is printed instead.
 func Error(v interface{}, data ...interface{}) {
 	defaultLogger.Error(v, data...)
 }
@@ -281,34 +664,199 @@ func CheckError(v interface{}, data ...interface{}) {
 	defaultLogger.CheckError(v, data...)
 }
 
-// Fatal prints a FATAL message with the stack trace.
+// ErrorReturn prints an ERROR message with the stack trace and returns err,
+// so that an error can be logged and returned in one expression:
+//
+//	if err != nil {
+//		return log.ErrorReturn(err, "user_id", id)
+//	}
+//
+// If err is nil, nothing is printed.
+func (l *Logger) ErrorReturn(err error, data ...interface{}) error {
+	if err != nil {
+		l.error(TypeError, err, data, 1)
+	}
+	return err
+}
+
+// ErrorReturn prints an ERROR message with the stack trace and returns err,
+// so that an error can be logged and returned in one expression. If err is
+// nil, nothing is printed.
+func ErrorReturn(err error, data ...interface{}) error {
+	return defaultLogger.ErrorReturn(err, data...)
+}
+
+// WarningReturn prints a WARNING message and returns err, so that an error can
+// be logged and returned in one expression. If err is nil, nothing is printed.
+func (l *Logger) WarningReturn(err error, data ...interface{}) error {
+	if err != nil {
+		l.send(TypeWarning, err, data)
+	}
+	return err
+}
+
+// WarningReturn prints a WARNING message and returns err, so that an error can
+// be logged and returned in one expression. If err is nil, nothing is printed.
+func WarningReturn(err error, data ...interface{}) error {
+	return defaultLogger.WarningReturn(err, data...)
+}
+
+var fatalExits bool
+
+// FatalExits sets whether Fatal exits the program with the status 1 after
+// printing the message and flushing the message queue, like log.Fatal does. It
+// is off by default so that Fatal can be used in tests.
+//
+// This function must not be called concurrently with the other functions of
+// this package.
+func FatalExits(b bool) {
+	fatalExits = b
+}
+
+// Fatal prints a FATAL message with the stack trace. It then exits the program
+// if FatalExits is on.
 func (l *Logger) Fatal(v interface{}, data ...interface{}) {
 	l.error(TypeFatal, v, data, 1)
+	if fatalExits {
+		exitWith(1)
+	}
 }
 
-// Fatal prints a FATAL message with the stack trace.
+// Fatal prints a FATAL message with the stack trace. It then exits the program
+// if FatalExits is on.
 func Fatal(v interface{}, data ...interface{}) {
 	defaultLogger.Fatal(v, data...)
 }
 
+// UserError prints a USER message. Use it for errors that are meant to be
+// shown to end users (e.g. a quota being exceeded) so that they are not
+// conflated with operational errors.
+//
+// The code identifies the error and is used to look up the user-facing text in
+// the catalog set with SetCatalog.
+func (l *Logger) UserError(code string, data ...interface{}) {
+	code, err := checkKey(code)
+	if err != nil {
+		l.sendError(err, 1)
+		return
+	}
+
+	mu.RLock()
+	text := catalog[code]
+	mu.RUnlock()
+	l.sendText(TypeUser, code, text, data)
+}
+
+// UserError prints a USER message. Use it for errors that are meant to be
+// shown to end users (e.g. a quota being exceeded) so that they are not
+// conflated with operational errors.
+//
+// The code identifies the error and is used to look up the user-facing text in
+// the catalog set with SetCatalog.
+func UserError(code string, data ...interface{}) {
+	defaultLogger.UserError(code, data...)
+}
+
+// Audit prints an AUDIT message. Use it to record the actions that must be
+// kept for security or compliance reasons (e.g. a user deleting an account):
+//
+//	AUDIT account.delete	| by="admin" account_id=42
+//
+// AUDIT messages are never sampled, rate limited nor dropped by the tenant
+// quotas. They can only be disabled with DisableType.
+func (l *Logger) Audit(action string, data ...interface{}) {
+	action, err := checkKey(action)
+	if err != nil {
+		l.sendError(err, 1)
+		return
+	}
+	if !l.isEnabled(TypeAudit) {
+		return
+	}
+
+	msg := getMessage()
+	msg.Type = TypeAudit
+	msg.Key = action
+	l.sendMessage(msg, data)
+}
+
+// Audit prints an AUDIT message. Use it to record the actions that must be
+// kept for security or compliance reasons (e.g. a user deleting an account).
+// AUDIT messages are never sampled, rate limited nor dropped by the tenant
+// quotas.
+func Audit(action string, data ...interface{}) {
+	defaultLogger.Audit(action, data...)
+}
+
+// A Catalog maps user error codes to their user-facing text.
+type Catalog map[string]string
+
+var catalog Catalog
+
+// SetCatalog sets the catalog used by UserError to look up the user-facing
+// text of error codes.
+func SetCatalog(c Catalog) {
+	mu.Lock()
+	catalog = c
+	mu.Unlock()
+}
+
 func (l *Logger) sendError(err error, skip int) {
 	l.error(TypeError, err, nil, skip+1)
 }
 
 func (l *Logger) error(typ Type, v interface{}, data []interface{}, skip int) {
+	if !l.shouldSend(typ) {
+		return
+	}
+
 	buf := getBuffer()
 	buf.appendValue(v)
+	if err, ok := v.(error); ok && errorChain != 0 {
+		data = buf.appendErrorChain(err, data)
+	}
+	if hasRateLimits() && !l.withinRateLimit(string(buf.buf)) {
+		putBuffer(buf)
+		return
+	}
+
+	// Prefer the stack trace captured by the error, if any.
+	var errPCs []uintptr
+	if err, ok := v.(error); ok && l.skipStackFrames >= 0 {
+		errPCs = errorCallers(err)
+	}
 
 	// Lock instead of RLock because getStackTrace is not concurrent-safe.
+	suppressed := false
+	var pcs []uintptr
+	var st string
 	mu.Lock()
 	if l.skipStackFrames >= 0 {
-		st := getStackTrace(l.skipStackFrames + skip + 1)
-		buf.appendString("\n\n")
-		buf.appendBytes(st)
+		if !withinTraceBudget(typ, buf.buf) {
+			suppressed = true
+		} else if errPCs != nil {
+			pcs = errPCs
+		} else if deferStackTraces {
+			pcs = callers(l.skipStackFrames + skip + 1)
+		} else {
+			st = string(getStackTrace(l.skipStackFrames + skip + 1))
+			if compactStackTraces {
+				st = compactStackTrace(st)
+			}
+		}
 	}
 	mu.Unlock()
 
-	l.send(typ, buf.String(), data)
+	if suppressed {
+		data = append(data[:len(data):len(data)], "trace_suppressed", true)
+	}
+
+	msg := getMessage()
+	msg.Type = typ
+	msg.Content = buf.String()
+	msg.stack = st
+	msg.pcs = pcs
+	l.sendMessage(msg, data)
 }
 
 const maxStackSize = 4000
@@ -340,24 +888,122 @@ func getStackTrace(skip int) []byte {
 }
 
 // CaptureStandardLog captures the log lines coming from the log package of the
-// standard library. Captured lines are output with an INFO level.
+// standard library. Captured lines are output with an INFO level, unless they
+// start with one of the prefixes set with SetLevelPrefixes, such as "ERROR:" or
+// "[WARN]".
 func (l *Logger) CaptureStandardLog() {
 	log.SetFlags(0)
-	log.SetOutput(stdLogWriter{l})
+	log.SetOutput(lineWriter{l, TypeInfo, true})
 }
 
 // CaptureStandardLog captures the log lines coming from the log package of the
-// standard library. Captured lines are output with an INFO level.
+// standard library. Captured lines are output with an INFO level, unless they
+// start with one of the prefixes set with SetLevelPrefixes, such as "ERROR:" or
+// "[WARN]".
 func CaptureStandardLog() {
 	defaultLogger.CaptureStandardLog()
 }
 
-type stdLogWriter struct {
+// Writer returns an io.Writer that prints a message of type typ for each line
+// written to it. Empty lines are ignored. It can be used with libraries that
+// only accept an io.Writer or a *log.Logger:
+//
+//	srv.ErrorLog = log.New(say.Writer(say.TypeWarning), "", 0)
+//
+// The type must be TypeDebug, TypeInfo, TypeWarning, TypeError or TypeFatal,
+// otherwise Writer panics.
+func (l *Logger) Writer(typ Type) io.Writer {
+	switch typ {
+	case TypeDebug, TypeInfo, TypeWarning, TypeError, TypeFatal:
+	default:
+		panic(errWriterType)
+	}
+	return lineWriter{l, typ, false}
+}
+
+// Writer returns an io.Writer that prints a message of type typ for each line
+// written to it. Empty lines are ignored. It can be used with libraries that
+// only accept an io.Writer or a *log.Logger:
+//
+//	srv.ErrorLog = log.New(say.Writer(say.TypeWarning), "", 0)
+//
+// The type must be TypeDebug, TypeInfo, TypeWarning, TypeError or TypeFatal,
+// otherwise Writer panics.
+func Writer(typ Type) io.Writer {
+	return defaultLogger.Writer(typ)
+}
+
+// CaptureReader prints a message of type typ for each line read from r, until
+// r returns io.EOF or another error, which is returned. A last line without a
+// newline is printed too. Empty lines are ignored. It can be used to capture
+// the output of a child process:
+//
+//	stderr, err := cmd.StderrPipe()
+//	...
+//	go log.CaptureReader(stderr, say.TypeWarning)
+//
+// The type must be TypeDebug, TypeInfo, TypeWarning, TypeError or TypeFatal,
+// otherwise CaptureReader panics.
+func (l *Logger) CaptureReader(r io.Reader, typ Type) error {
+	w := l.Writer(typ)
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		if line != "" {
+			w.Write([]byte(line))
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// CaptureReader prints a message of type typ for each line read from r, until
+// r returns io.EOF or another error, which is returned. A last line without a
+// newline is printed too. Empty lines are ignored.
+//
+// The type must be TypeDebug, TypeInfo, TypeWarning, TypeError or TypeFatal,
+// otherwise CaptureReader panics.
+func CaptureReader(r io.Reader, typ Type) error {
+	return defaultLogger.CaptureReader(r, typ)
+}
+
+type lineWriter struct {
 	*Logger
+	typ    Type
+	detect bool // Whether the type is detected from the level prefixes.
 }
 
-func (w stdLogWriter) Write(p []byte) (int, error) {
-	w.Info(string(p[:len(p)-1])) // Remove the trailing newline.
+func (w lineWriter) Write(p []byte) (int, error) {
+	s := string(p)
+	for s != "" {
+		line := s
+		if i := strings.IndexByte(s, '\n'); i != -1 {
+			line, s = s[:i], s[i+1:]
+		} else {
+			s = ""
+		}
+		if line == "" {
+			continue
+		}
+
+		typ := w.typ
+		if w.detect {
+			typ, line = detectLevel(typ, line)
+		}
+		switch typ {
+		case TypeDebug:
+			w.Debug(line)
+		case TypeError, TypeFatal:
+			w.error(typ, line, nil, 1)
+		default:
+			w.send(typ, line, nil)
+		}
+	}
 	return len(p), nil
 }
 
@@ -365,34 +1011,68 @@ var debug bool
 
 // SetDebug sets whether Say is in debug mode. The debug mode is off by default.
 //
+// When building with the saydiscard_debug tag, the debug mode is always off.
+//
 // This function must not be called concurrently with the other functions of
 // this package.
 func SetDebug(b bool) {
 	debug = b
 }
 
+// DebugEnabled reports whether Say is in debug mode. It is inlined by the
+// compiler so it can guard expensive Debug calls:
+//
+//	if say.DebugEnabled() {
+//		say.Debug("State dump", "state", expensiveDump())
+//	}
+//
+// When building with the saydiscard_debug tag, DebugEnabled is constant false
+// and the guarded code, including the evaluation of the arguments, is removed
+// from the binary.
+func DebugEnabled() bool {
+	return !discardDebug && debug
+}
+
 // A Hook is a function used to provide dynamic Data values.
 type Hook func() interface{}
 
 // DebugHook allows printing a key-value pairs only when Say is in debug mode.
 func DebugHook(v interface{}) Hook {
 	return Hook(func() interface{} {
-		if debug {
+		if DebugEnabled() {
 			return v
 		}
 		return nil
 	})
 }
 
-// TimeHook prints the current time.
+// TimeHook prints the current time in the location set with SetTimeFormat. An
+// empty format uses the layout set with SetTimeFormat.
 func TimeHook(format string) Hook {
 	return Hook(func() interface{} {
-		return now().Format(format)
+		if format == "" {
+			buf := getBuffer()
+			buf.appendTimestamp(now())
+			return buf.String()
+		}
+		return inTimeLocation(now()).Format(format)
 	})
 }
 
+var clock = sayclock.Real
+
+// SetClock sets the clock used to timestamp messages and measure durations
+// with Timing and TimeHook. It is sayclock.Real by default.
+//
+// This function must not be called concurrently with the other functions of
+// this package.
+func SetClock(c sayclock.Clock) {
+	clock = c
+}
+
+func now() time.Time {
+	return clock.Now()
+}
+
 // Stubbed out for testing.
-var (
-	now          = time.Now
-	runtimeStack = runtime.Stack
-)
+var runtimeStack = runtime.Stack
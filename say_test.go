@@ -3,12 +3,19 @@ package say
 import (
 	"bytes"
 	"errors"
+	"io"
 	"io/ioutil"
 	"log"
+	"runtime"
+	"runtime/trace"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
+	"testing/iotest"
 	"time"
+
+	"gopkg.in/say.v0/sayclock"
 )
 
 func init() {
@@ -56,24 +63,53 @@ func TestValue(t *testing.T) {
 	})
 }
 
+func TestHistogram(t *testing.T) {
+	expect(t, func() {
+		Histogram("request_size", 512)
+		NewLogger(Name("http")).Histogram("latency", 1.5, "a", 1)
+	}, []string{
+		"HISTO request_size:512",
+		`HISTO http.latency:1.5	| logger="http" a=1`,
+	})
+}
+
+func TestUnique(t *testing.T) {
+	expect(t, func() {
+		Unique("active_users", 42)
+		NewLogger(Name("web")).Unique("visitors", "10.0.0.1", "a", 1)
+		Unique("foo", "")
+	}, []string{
+		"UNIQ  active_users:42",
+		`UNIQ  web.visitors:10.0.0.1	| logger="web" a=1`,
+		"UNIQ  foo",
+	})
+}
+
+func TestValueUnit(t *testing.T) {
+	expect(t, func() {
+		ValueUnit("payload_size", 1234, "B")
+		NewLogger().ValueUnit("ratio", 0.5, "%", "a", 1)
+		ValueUnit("foo", "bar", "B")
+		ValueUnit("foo", 1, "k B")
+	}, []string{
+		"VALUE payload_size:1234B",
+		"VALUE ratio:0.5%	| a=1",
+		"ERROR say: ValueUnit only accepts numbers",
+		"ERROR say: units must only contain letters, '%' or '/'",
+	})
+}
+
 func TestTiming(t *testing.T) {
-	i := 0
 	date := time.Date(2015, 9, 1, 21, 37, 0, 0, time.UTC)
-	now = func() time.Time {
-		i++
-		switch i {
-		default:
-			return date
-		case 2:
-			return date.Add(100 * time.Millisecond)
-		case 3:
-			return date.Add(time.Second)
-		}
-	}
+	c := sayclock.NewFake(date)
+	SetClock(c)
+	defer SetClock(sayclock.Real)
 
 	expect(t, func() {
 		timing := NewTiming()
+		c.Add(100 * time.Millisecond)
 		timing.Say("test.timing")
+		c.Add(900 * time.Millisecond)
 		if timing.Get() != time.Second {
 			t.Errorf("Timing.Get() = %v, want %v", timing.Get(), time.Second)
 		}
@@ -82,6 +118,52 @@ func TestTiming(t *testing.T) {
 	})
 }
 
+func TestTraceRegions(t *testing.T) {
+	TraceRegions(true)
+	defer TraceRegions(false)
+
+	buf := new(bytes.Buffer)
+	if err := trace.Start(buf); err != nil {
+		t.Skip(err)
+	}
+	w := Mute()
+	ran := false
+	Time("test.region", func() { ran = true })
+	Job("test.task", func() error { return nil })
+	Redirect(w)
+	trace.Stop()
+
+	if !ran {
+		t.Error("Time did not run f")
+	}
+	for _, name := range []string{"test.region", "test.task"} {
+		if !bytes.Contains(buf.Bytes(), []byte(name)) {
+			t.Errorf("the execution trace does not contain %q", name)
+		}
+	}
+}
+
+func TestTimingClockGoesBackwards(t *testing.T) {
+	date := time.Date(2015, 9, 1, 21, 37, 0, 0, time.UTC)
+	c := sayclock.NewFake(date)
+	SetClock(c)
+	defer SetClock(sayclock.Real)
+
+	expect(t, func() {
+		timing := NewTiming()
+		c.Add(-time.Second)
+		timing.Say("test.timing")
+		if d := timing.Get(); d != 0 {
+			t.Errorf("Timing.Get() = %v, want 0", d)
+		}
+		if d := timing.GetMonotonic(); d < 0 || d > time.Second {
+			t.Errorf("Timing.GetMonotonic() = %v, want a small positive duration", d)
+		}
+	}, []string{
+		"VALUE test.timing:0ms",
+	})
+}
+
 func TestGauge(t *testing.T) {
 	expect(t, func() {
 		Gauge("test.gauge", 10)
@@ -90,7 +172,41 @@ func TestGauge(t *testing.T) {
 	})
 }
 
+func TestGaugeDelta(t *testing.T) {
+	expect(t, func() {
+		GaugeAdd("test.gauge", 2)
+		GaugeSub("test.gauge", 1.5, "a", 1)
+		GaugeAdd("test.gauge", -3)
+		NewLogger().GaugeSub("test.gauge", 0)
+	}, []string{
+		"GAUGE test.gauge:+2",
+		"GAUGE test.gauge:-1.5	| a=1",
+		"GAUGE test.gauge:-3",
+		"GAUGE test.gauge:+0",
+	})
+
+	tests := []struct {
+		m    Message
+		want float64
+		ok   bool
+	}{
+		{Message{Type: TypeGauge, Key: "a", Content: "+2"}, 2, true},
+		{Message{Type: TypeGauge, Key: "a", Content: "-1.5"}, -1.5, true},
+		{Message{Type: TypeGauge, Key: "a", Value: -3}, 0, false},
+		{Message{Type: TypeGauge, Key: "a", Content: "foo"}, 0, false},
+		{Message{Type: TypeValue, Key: "a", Content: "+2"}, 0, false},
+	}
+	for _, tt := range tests {
+		if got, ok := tt.m.GaugeDelta(); got != tt.want || ok != tt.ok {
+			t.Errorf("GaugeDelta() of %+v = (%v, %v), want (%v, %v)", tt.m, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
 func TestDebug(t *testing.T) {
+	if discardDebug {
+		t.Skip("DEBUG messages are discarded with the saydiscard_debug tag")
+	}
 	expect(t, func() {
 		Debug("foo")
 		Info("foo", "debug", DebugHook(45))
@@ -113,6 +229,39 @@ func TestDebug(t *testing.T) {
 	})
 }
 
+func TestLazyMessage(t *testing.T) {
+	calls := 0
+	msg := func() string {
+		calls++
+		return "foo"
+	}
+	expect(t, func() {
+		Debug(msg)
+		NewLogger(Sampling(0)).Info(msg)
+		Info(msg)
+		Info(func() interface{} { return 42 })
+		Warning(msg)
+	}, []string{
+		"INFO  foo",
+		"INFO  42",
+		"WARN  foo",
+	})
+	if calls != 2 {
+		t.Errorf("the message has been built %d times, want 2", calls)
+	}
+}
+
+func TestDebugEnabled(t *testing.T) {
+	if DebugEnabled() {
+		t.Error("DebugEnabled() = true, want false")
+	}
+	SetDebug(true)
+	if got, want := DebugEnabled(), !discardDebug; got != want {
+		t.Errorf("DebugEnabled() = %v after SetDebug(true), want %v", got, want)
+	}
+	SetDebug(false)
+}
+
 func TestInfo(t *testing.T) {
 	expect(t, func() {
 		Info("Test message!")
@@ -158,6 +307,27 @@ func TestCheckError(t *testing.T) {
 	})
 }
 
+func TestErrorReturn(t *testing.T) {
+	errTest := errors.New("Test error")
+	expect(t, func() {
+		if err := ErrorReturn(nil); err != nil {
+			t.Errorf("ErrorReturn(nil) = %v, want nil", err)
+		}
+		if err := ErrorReturn(errTest, "a", 1); err != errTest {
+			t.Errorf("ErrorReturn() = %v, want %v", err, errTest)
+		}
+		if err := WarningReturn(nil); err != nil {
+			t.Errorf("WarningReturn(nil) = %v, want nil", err)
+		}
+		if err := NewLogger().WarningReturn(errTest); err != errTest {
+			t.Errorf("WarningReturn() = %v, want %v", err, errTest)
+		}
+	}, []string{
+		"ERROR Test error	| a=1",
+		"WARN  Test error",
+	})
+}
+
 func TestFatal(t *testing.T) {
 	expect(t, func() {
 		Fatal("Test fatal")
@@ -166,6 +336,107 @@ func TestFatal(t *testing.T) {
 	})
 }
 
+func TestFatalExits(t *testing.T) {
+	var codes []int
+	exit = func(code int) {
+		codes = append(codes, code)
+	}
+	defer func() {
+		exit = func(int) {}
+	}()
+
+	expect(t, func() {
+		Fatal("foo")
+		FatalExits(true)
+		Fatal("bar")
+		NewLogger().Fatal("baz")
+		FatalExits(false)
+	}, []string{
+		"FATAL foo",
+		"FATAL bar",
+		"FATAL baz",
+	})
+	if len(codes) != 2 || codes[0] != 1 || codes[1] != 1 {
+		t.Errorf("exit codes = %v, want [1 1]", codes)
+	}
+}
+
+func TestUserError(t *testing.T) {
+	SetCatalog(Catalog{"ERR_QUOTA": "Your quota is exceeded."})
+	defer SetCatalog(nil)
+
+	expect(t, func() {
+		UserError("ERR_QUOTA", "quota", 10)
+		UserError("ERR_UNKNOWN")
+	}, []string{
+		"USER  ERR_QUOTA:Your quota is exceeded.	| quota=10",
+		"USER  ERR_UNKNOWN",
+	})
+}
+
+func TestAudit(t *testing.T) {
+	c := sayclock.NewFake(time.Date(2015, 9, 1, 21, 37, 0, 0, time.UTC))
+	SetClock(c)
+	defer SetClock(sayclock.Real)
+	SetTenantQuota(1, time.Minute)
+	defer SetTenantQuota(0, 0)
+	RateLimit("account.delete", 1, time.Minute)
+	defer RateLimit("account.delete", 0, 0)
+
+	expect(t, func() {
+		log := ForTenant("acme").NewLogger(Sampling(0))
+		log.Info("foo")
+		log.Audit("account.delete", "account_id", 42)
+		log.Audit("account.delete", "account_id", 43)
+		Audit("")
+		log.DisableType(TypeAudit)
+		log.Audit("account.delete")
+	}, []string{
+		`AUDIT account.delete	| tenant="acme" account_id=42`,
+		`AUDIT account.delete	| tenant="acme" account_id=43`,
+		`ERROR say: key is empty`,
+	})
+}
+
+func TestRedirectUser(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := RedirectUser(buf)
+	defer RedirectUser(w)
+
+	expect(t, func() {
+		Info("foo")
+		UserError("ERR_QUOTA")
+	}, []string{
+		"INFO  foo",
+	})
+	if got, want := buf.String(), "USER  ERR_QUOTA\n"; got != want {
+		t.Errorf("invalid user output, got %q, want %q", got, want)
+	}
+}
+
+func TestDisableType(t *testing.T) {
+	expect(t, func() {
+		log := NewLogger(SkipStackFrames(-1))
+		DisableType(TypeValue)
+		log.DisableType(TypeError)
+		child := log.NewLogger()
+		log.EnableType(TypeError)
+
+		Value("foo", 1)
+		Event("foo")
+		log.Error("foo")
+		child.Error("foo")
+		child.Info("foo")
+		EnableType(TypeValue)
+		Value("foo", 2)
+	}, []string{
+		"EVENT foo",
+		"ERROR foo",
+		"INFO  foo",
+		"VALUE foo:2",
+	})
+}
+
 func TestMultiline(t *testing.T) {
 	expect(t, func() {
 		Info("foo\nbar \nbaz ")
@@ -197,6 +468,25 @@ func TestData(t *testing.T) {
 	})
 }
 
+func TestJSONData(t *testing.T) {
+	expect(t, func() {
+		log := NewLogger(JSONData(true))
+		log.Info("foo")
+		log.Info("foo", "a", 1, "b", "x\"y", "c", true)
+		log.Info("foo", "a", 1, "a", 2, "d", DebugHook(1))
+		log.Info("foo", "d", DebugHook(1))
+		log.NewLogger().Event("bar", "f", 1.5)
+		NewLogger().Event("bar", "f", 1.5)
+	}, []string{
+		`INFO  foo`,
+		`INFO  foo	| {"a":1,"b":"x\"y","c":true}`,
+		`INFO  foo	| {"a":2}`,
+		`INFO  foo`,
+		`EVENT bar	| {"f":1.5}`,
+		`EVENT bar	| f=1.5`,
+	})
+}
+
 func TestNewLogger(t *testing.T) {
 	expect(t, func() {
 		SetData("foo", "bar")
@@ -207,11 +497,146 @@ func TestNewLogger(t *testing.T) {
 	})
 }
 
+func TestNewLoggerDataIsolation(t *testing.T) {
+	expect(t, func() {
+		parent := new(Logger)
+		parent.SetData("a", 1, "b", 2)
+		parent.SetData("a", 1)
+		child1 := parent.NewLogger()
+		child2 := parent.NewLogger()
+		child1.AddData("c", 3)
+		child2.AddData("d", 4)
+		child2.SetData("e", 5)
+		parent.Info("foo")
+		child1.Info("foo")
+		child2.Info("foo")
+	}, []string{
+		"INFO  foo	| a=1",
+		"INFO  foo	| a=1 c=3",
+		"INFO  foo	| e=5",
+	})
+}
+
+func TestName(t *testing.T) {
+	expect(t, func() {
+		log := NewLogger(Name("http.server"))
+		log.Event("request")
+		log.Info("foo", "a", 1)
+		log.NewLogger(Name("tls")).Value("handshake", 5)
+	}, []string{
+		`EVENT http.server.request	| logger="http.server"`,
+		`INFO  foo	| logger="http.server" a=1`,
+		`VALUE http.server.tls.handshake:5	| logger="http.server.tls"`,
+	})
+
+	expect(t, func() {
+		db := Name("db")
+		NewLogger(Name("a")).NewLogger(db).Event("q")
+		NewLogger(Name("b")).NewLogger(db).Event("q")
+		NewLogger(db).Event("q")
+	}, []string{
+		`EVENT a.db.q	| logger="a.db"`,
+		`EVENT b.db.q	| logger="b.db"`,
+		`EVENT db.q	| logger="db"`,
+	})
+
+	SetKeyPolicy(TransliterateKeys)
+	defer SetKeyPolicy(AllowKeys)
+	expect(t, func() {
+		NewLogger(Name("café")).Event("q")
+	}, []string{
+		`EVENT cafe.q	| logger="cafe"`,
+	})
+
+	for _, tt := range []struct {
+		name string
+		err  error
+	}{
+		{"", errKeyEmpty},
+		{"http\tserver", errKeyInvalid},
+		{"http\nserver", errKeyInvalid},
+	} {
+		func() {
+			defer func() {
+				if err := recover(); err != tt.err {
+					t.Errorf("Name(%q) = %v, want %v", tt.name, err, tt.err)
+				}
+			}()
+			Name(tt.name)
+		}()
+	}
+}
+
+func TestWithCaller(t *testing.T) {
+	var sites []string
+	here := func() {
+		_, file, line, _ := runtime.Caller(1)
+		sites = append(sites, shortFile(file)+":"+strconv.Itoa(line-1))
+	}
+	log := NewLogger(WithCaller())
+	helper := func(msg string) {
+		log.NewLogger(CallerSkip(1)).Info(msg)
+	}
+
+	var buf bytes.Buffer
+	w := Redirect(&buf)
+	log.Info("foo")
+	here()
+	log.Event("bar")
+	here()
+	helper("baz")
+	here()
+	Redirect(w)
+
+	want := ""
+	for i, msg := range []string{"INFO  foo", "EVENT bar", "INFO  baz"} {
+		want += msg + "\t| caller=\"" + sites[i] + "\"\n"
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestTTL(t *testing.T) {
+	var ttls []time.Duration
+	SetListener(func(m *Message) {
+		ttls = append(ttls, m.TTL)
+	})
+	log := NewLogger(TTL(time.Minute))
+	log.Gauge("foo", 5)
+	log.NewLogger().Info("bar")
+	Gauge("foo", 5)
+	Flush()
+	SetListener(nil)
+
+	want := []time.Duration{time.Minute, time.Minute, 0}
+	if len(ttls) != len(want) || ttls[0] != want[0] || ttls[1] != want[1] || ttls[2] != want[2] {
+		t.Errorf("TTLs = %v, want %v", ttls, want)
+	}
+
+	expect(t, func() {
+		log.Gauge("foo", 5)
+	}, []string{
+		"GAUGE foo:5	| ttl_ms=60000",
+	})
+}
+
 func TestTimeHook(t *testing.T) {
+	SetClock(sayclock.NewFake(time.Date(2015, 9, 1, 21, 37, 0, 0, time.UTC)))
+	defer SetClock(sayclock.Real)
+
 	expect(t, func() {
 		Info("foo", "timestamp", TimeHook("2006-01-02 15:04:05"))
+		Info("foo", "timestamp", TimeHook(""))
+		SetTimeFormat(time.Kitchen, time.FixedZone("", -3600))
+		Info("foo", "timestamp", TimeHook("2006-01-02 15:04:05"))
+		Info("foo", "timestamp", TimeHook(""))
+		SetTimeFormat(DefaultTimeLayout, nil)
 	}, []string{
 		`INFO  foo	| timestamp="2015-09-01 21:37:00"`,
+		`INFO  foo	| timestamp="2015-09-01 21:37:00.000"`,
+		`INFO  foo	| timestamp="2015-09-01 20:37:00"`,
+		`INFO  foo	| timestamp="8:37PM"`,
 	})
 }
 
@@ -234,11 +659,11 @@ func TestInvalidData(t *testing.T) {
 		"INFO  foo",
 		"ERROR " + errKeyEmpty.Error(),
 		"ERROR " + errKeyInvalid.Error(),
+		`EVENT foo\:`,
+		`EVENT foo\=:2`,
 		"ERROR " + errKeyInvalid.Error(),
-		"ERROR " + errKeyInvalid.Error(),
-		"ERROR " + errKeyInvalid.Error(),
-		"ERROR " + errKeyInvalid.Error(),
-		"ERROR " + errKeyInvalid.Error(),
+		`VALUE \=foo:2`,
+		`VALUE \:foo:0ms`,
 		"ERROR " + errOddNumArgs.Error(),
 		"INFO  foo",
 		"ERROR " + errKeyNotString.Error(),
@@ -254,14 +679,24 @@ func TestInvalidKeys(t *testing.T) {
 		log.Event("")
 		log.Event("\n")
 		log.Event("foo\t")
-		log.Event("foo:bar")
-		log.Event("=bar")
 	}, []string{
 		"ERROR " + errKeyEmpty.Error(),
 		"ERROR " + errKeyInvalid.Error(),
 		"ERROR " + errKeyInvalid.Error(),
-		"ERROR " + errKeyInvalid.Error(),
-		"ERROR " + errKeyInvalid.Error(),
+	})
+}
+
+func TestEscapedKeys(t *testing.T) {
+	expect(t, func() {
+		Event("foo:bar")
+		Event("=bar")
+		Value(`C:\Users`, 5)
+		Info("foo", "http://example.com/?a=b", 1)
+	}, []string{
+		`EVENT foo\:bar`,
+		`EVENT \=bar`,
+		`VALUE C\:\\Users:5`,
+		`INFO  foo	| http\://example.com/?a\=b=1`,
 	})
 }
 
@@ -313,7 +748,7 @@ func testStackFrames(t *testing.T, skip int, mustHave, mustNotHave []string) {
 	log.Fatal("bar")
 
 	mustNotHave = append(mustNotHave, []string{
-		"goroutine",
+		"[running]",
 		"/say.go:",
 	}...)
 
@@ -341,6 +776,44 @@ func TestCaptureStandardLog(t *testing.T) {
 	})
 }
 
+func TestWriter(t *testing.T) {
+	expect(t, func() {
+		w := NewLogger().Writer(TypeWarning)
+		w.Write([]byte("foo\nbar\n\nbaz"))
+		log.New(Writer(TypeError), "", 0).Print("qux")
+	}, []string{
+		"WARN  foo",
+		"WARN  bar",
+		"WARN  baz",
+		"ERROR qux",
+	})
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Writer(TypeEvent) did not panic")
+		}
+	}()
+	Writer(TypeEvent)
+}
+
+func TestCaptureReader(t *testing.T) {
+	expect(t, func() {
+		r := strings.NewReader("foo\r\n\nbar\nbaz")
+		if err := NewLogger().CaptureReader(r, TypeWarning); err != nil {
+			t.Errorf("CaptureReader() = %v, want nil", err)
+		}
+		r2 := io.MultiReader(strings.NewReader("qux"), iotest.ErrReader(errors.New("closed")))
+		if err := CaptureReader(r2, TypeInfo); err == nil || err.Error() != "closed" {
+			t.Errorf("CaptureReader() = %v, want closed", err)
+		}
+	}, []string{
+		"WARN  foo",
+		"WARN  bar",
+		"WARN  baz",
+		"INFO  qux",
+	})
+}
+
 func TestRace(t *testing.T) {
 	w := Redirect(ioutil.Discard)
 	defer Redirect(w)
@@ -443,6 +916,15 @@ func BenchmarkTiming(b *testing.B) {
 	}
 }
 
+func BenchmarkTimingListener(b *testing.B) {
+	SetListener(func(*Message) {})
+	defer SetListener(nil)
+	for i := 0; i < b.N; i++ {
+		NewTiming().Say("timing")
+	}
+	Flush()
+}
+
 func BenchmarkGauge(b *testing.B) {
 	out = ioutil.Discard
 	for i := 0; i < b.N; i++ {
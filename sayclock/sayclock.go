@@ -0,0 +1,161 @@
+// Package sayclock provides the clocks used by Say to timestamp messages and
+// measure durations.
+//
+// Tests can use a Fake clock to get deterministic timings:
+//
+//	clock := sayclock.NewFake(time.Date(2015, 9, 1, 21, 37, 0, 0, time.UTC))
+//	say.SetClock(clock)
+//	t := say.NewTiming()
+//	clock.Add(100 * time.Millisecond)
+//	t.Say("duration") // VALUE duration:100ms
+//
+// A Fake clock also drives the periodic work of Say, e.g. the flushing of
+// aggregated metrics: its tickers tick when the clock is advanced.
+package sayclock
+
+import (
+	"sync"
+	"time"
+)
+
+// A Clock tells the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// A TickerClock is a Clock that delivers ticks on its own time. Clocks that
+// are not TickerClocks tick with the clock of the system.
+type TickerClock interface {
+	Clock
+	NewTicker(d time.Duration) Ticker
+}
+
+// A Ticker delivers ticks at intervals.
+type Ticker interface {
+	// C returns the channel on which the ticks are delivered.
+	C() <-chan time.Time
+	// Stop turns off the ticker. No more ticks are delivered afterwards.
+	Stop()
+}
+
+// NewTicker returns a Ticker ticking every d on c if c is a TickerClock, or on
+// the clock of the system otherwise. It panics if d is not positive.
+func NewTicker(c Clock, d time.Duration) Ticker {
+	if tc, ok := c.(TickerClock); ok {
+		return tc.NewTicker(d)
+	}
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTicker struct {
+	t *time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.t.C
+}
+
+func (t realTicker) Stop() {
+	t.t.Stop()
+}
+
+// Real is the clock of the system. It is the default clock of Say.
+var Real Clock = Func(time.Now)
+
+// Func is an adapter to allow the use of ordinary functions as clocks.
+type Func func() time.Time
+
+// Now returns f().
+func (f Func) Now() time.Time {
+	return f()
+}
+
+// A Fake is a clock whose time only changes when told so. It is safe for
+// concurrent use.
+type Fake struct {
+	mu      sync.Mutex
+	t       time.Time
+	tickers []*fakeTicker
+}
+
+// NewFake returns a Fake clock set at t.
+func NewFake(t time.Time) *Fake {
+	return &Fake{t: t}
+}
+
+// Now returns the current time of the clock.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	t := f.t
+	f.mu.Unlock()
+	return t
+}
+
+// Set sets the current time of the clock. The tickers whose next tick is due
+// tick once.
+func (f *Fake) Set(t time.Time) {
+	f.mu.Lock()
+	f.t = t
+	f.tick()
+	f.mu.Unlock()
+}
+
+// Add advances the clock by d. The tickers whose next tick is due tick once.
+func (f *Fake) Add(d time.Duration) {
+	f.mu.Lock()
+	f.t = f.t.Add(d)
+	f.tick()
+	f.mu.Unlock()
+}
+
+// NewTicker returns a Ticker ticking every d of the time of the clock. Like
+// a time.Ticker, it drops the ticks that are not received in time. It panics
+// if d is not positive.
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("sayclock: non-positive interval for NewTicker")
+	}
+	f.mu.Lock()
+	t := &fakeTicker{f: f, c: make(chan time.Time, 1), d: d, next: f.t.Add(d)}
+	f.tickers = append(f.tickers, t)
+	f.mu.Unlock()
+	return t
+}
+
+// tick sends the ticks that are due. It must be called with f.mu locked.
+func (f *Fake) tick() {
+	for _, t := range f.tickers {
+		if f.t.Before(t.next) {
+			continue
+		}
+		select {
+		case t.c <- f.t:
+		default:
+		}
+		for !f.t.Before(t.next) {
+			t.next = t.next.Add(t.d)
+		}
+	}
+}
+
+type fakeTicker struct {
+	f    *Fake
+	c    chan time.Time
+	d    time.Duration
+	next time.Time
+}
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTicker) Stop() {
+	t.f.mu.Lock()
+	for i, u := range t.f.tickers {
+		if u == t {
+			t.f.tickers = append(t.f.tickers[:i], t.f.tickers[i+1:]...)
+			break
+		}
+	}
+	t.f.mu.Unlock()
+}
@@ -0,0 +1,71 @@
+package sayclock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFake(t *testing.T) {
+	start := time.Date(2015, 9, 1, 21, 37, 0, 0, time.UTC)
+	c := NewFake(start)
+	if got := c.Now(); !got.Equal(start) {
+		t.Errorf("Now() = %v, want %v", got, start)
+	}
+
+	c.Add(time.Second)
+	if got, want := c.Now(), start.Add(time.Second); !got.Equal(want) {
+		t.Errorf("Now() = %v after Add, want %v", got, want)
+	}
+
+	c.Set(start)
+	if got := c.Now(); !got.Equal(start) {
+		t.Errorf("Now() = %v after Set, want %v", got, start)
+	}
+}
+
+func TestFunc(t *testing.T) {
+	date := time.Date(2015, 11, 25, 15, 47, 0, 0, time.UTC)
+	c := Func(func() time.Time { return date })
+	if got := c.Now(); !got.Equal(date) {
+		t.Errorf("Now() = %v, want %v", got, date)
+	}
+}
+
+func TestFakeTicker(t *testing.T) {
+	start := time.Date(2015, 9, 1, 21, 37, 0, 0, time.UTC)
+	c := NewFake(start)
+	tk := NewTicker(c, time.Minute)
+
+	c.Add(30 * time.Second)
+	select {
+	case <-tk.C():
+		t.Fatal("ticked before the interval elapsed")
+	default:
+	}
+
+	c.Add(3 * time.Minute)
+	if got, want := <-tk.C(), start.Add(210*time.Second); !got.Equal(want) {
+		t.Errorf("tick at %v, want %v", got, want)
+	}
+	select {
+	case <-tk.C():
+		t.Fatal("ticked twice for one Add")
+	default:
+	}
+
+	c.Add(time.Minute)
+	<-tk.C()
+	tk.Stop()
+	c.Add(time.Minute)
+	select {
+	case <-tk.C():
+		t.Fatal("ticked after Stop")
+	default:
+	}
+}
+
+func TestRealTicker(t *testing.T) {
+	tk := NewTicker(Real, time.Millisecond)
+	<-tk.C()
+	tk.Stop()
+}
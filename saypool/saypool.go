@@ -0,0 +1,80 @@
+// Package saypool runs tasks on a pool of workers and reports the standard
+// metrics of the pool with Say.
+//
+// A pool named "thumbnails" sends the following messages:
+//
+//	GAUGE thumbnails.queue_depth:3	| logger="thumbnails"
+//	VALUE thumbnails.task:12ms	| logger="thumbnails" worker_id=2
+//	EVENT thumbnails.panic	| logger="thumbnails" worker_id=2
+//
+// The tasks are given the Logger of the worker running them so that their own
+// messages carry the worker_id data too.
+package saypool
+
+import (
+	"fmt"
+	"sync"
+
+	"gopkg.in/say.v0"
+)
+
+// A Task is a function run by a worker of a Pool. The Logger is the Logger of
+// the worker.
+type Task func(log *say.Logger)
+
+// A Pool runs tasks on a fixed number of workers.
+type Pool struct {
+	log   *say.Logger
+	tasks chan Task
+	wg    sync.WaitGroup
+}
+
+// New starts a Pool of n workers named name. Up to queueSize tasks can wait
+// for a worker before Submit blocks. The Loggers of the pool and its workers
+// are created from log with the Name option.
+func New(log *say.Logger, name string, n, queueSize int) *Pool {
+	p := &Pool{
+		log:   log.NewLogger(say.Name(name)),
+		tasks: make(chan Task, queueSize),
+	}
+	p.wg.Add(n)
+	for i := 1; i <= n; i++ {
+		go p.work(p.log.With("worker_id", i))
+	}
+	return p
+}
+
+// Submit queues a task and sends a GAUGE queue_depth with the number of
+// waiting tasks. It blocks while the queue is full. It must not be called
+// after Close.
+func (p *Pool) Submit(task Task) {
+	p.tasks <- task
+	p.log.Gauge("queue_depth", len(p.tasks))
+}
+
+// Close waits for the queued tasks to run and stops the workers.
+func (p *Pool) Close() {
+	close(p.tasks)
+	p.wg.Wait()
+}
+
+func (p *Pool) work(log *say.Logger) {
+	defer p.wg.Done()
+	for task := range p.tasks {
+		run(log, task)
+	}
+}
+
+// run runs task and sends a VALUE task with its duration. If task panics, the
+// panic is recovered, printed as an ERROR and counted with an EVENT panic.
+func run(log *say.Logger, task Task) {
+	t := log.NewTiming()
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error(fmt.Sprint("panic: ", r))
+			log.Event("panic")
+		}
+		t.Say("task")
+	}()
+	task(log)
+}
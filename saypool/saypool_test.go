@@ -0,0 +1,32 @@
+package saypool
+
+import (
+	"testing"
+
+	"gopkg.in/say.v0"
+	"gopkg.in/say.v0/saytest"
+)
+
+func TestPool(t *testing.T) {
+	say.DisableStackTraces(true)
+	defer say.DisableStackTraces(false)
+
+	// Keep the worker busy so that the queue depth is deterministic.
+	w := say.Mute()
+	p := New(say.NewLogger(), "jobs", 1, 10)
+	started := make(chan struct{})
+	block := make(chan struct{})
+	p.Submit(func(*say.Logger) {
+		close(started)
+		<-block
+	})
+	<-started
+	say.Redirect(w)
+
+	saytest.Golden(t, "testdata/pool.golden", func() {
+		p.Submit(func(log *say.Logger) { log.Info("hello") })
+		p.Submit(func(*say.Logger) { panic("oops") })
+		close(block)
+		p.Close()
+	})
+}
@@ -0,0 +1,59 @@
+package saytest
+
+import (
+	"math/rand"
+	"time"
+
+	"gopkg.in/say.v0"
+)
+
+// Chaos describes how to degrade the delivery of messages to a listener, to
+// check that an application or a listener behaves correctly when the
+// telemetry path is slow or lossy.
+type Chaos struct {
+	// Seed seeds the random choices so that a run can be reproduced.
+	Seed int64
+	// Drop is the probability that a message is dropped.
+	Drop float64
+	// Reorder is the probability that a message is held back and delivered
+	// right after the next message.
+	Reorder float64
+	// MaxDelay is the maximum delay added before delivering each message.
+	MaxDelay time.Duration
+}
+
+// Listener wraps f so that the messages are degraded as described by c. The
+// returned function is meant to be passed to say.SetListener:
+//
+//	say.SetListener(saytest.Chaos{Seed: 1, Drop: 0.1}.Listener(listener))
+//
+// A message held back to be reordered is only delivered with the next message,
+// so the last message may never be delivered.
+func (c Chaos) Listener(f func(*say.Message)) func(*say.Message) {
+	r := rand.New(rand.NewSource(c.Seed))
+	var held *say.Message
+	return func(m *say.Message) {
+		if c.MaxDelay > 0 {
+			time.Sleep(time.Duration(r.Int63n(int64(c.MaxDelay) + 1)))
+		}
+		if r.Float64() < c.Drop {
+			return
+		}
+		if held == nil && r.Float64() < c.Reorder {
+			// Messages are reused once the listener returns.
+			held = copyMessage(m)
+			return
+		}
+		f(m)
+		if held != nil {
+			f(held)
+			held = nil
+		}
+	}
+}
+
+func copyMessage(m *say.Message) *say.Message {
+	cp := *m
+	cp.Data = append(say.Data(nil), m.Data...)
+	return &cp
+}
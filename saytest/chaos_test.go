@@ -0,0 +1,43 @@
+package saytest
+
+import (
+	"strconv"
+	"testing"
+
+	"gopkg.in/say.v0"
+)
+
+func TestChaos(t *testing.T) {
+	const n = 1000
+	var keys []string
+	say.SetListener(Chaos{Seed: 1, Drop: 0.2, Reorder: 0.2}.Listener(func(m *say.Message) {
+		keys = append(keys, m.Key)
+	}))
+	for i := 0; i < n; i++ {
+		say.Event(strconv.Itoa(i))
+	}
+	say.Flush()
+	say.SetListener(nil)
+
+	if len(keys) < n/2 || len(keys) > n-n/10 {
+		t.Errorf("%d messages out of %d delivered with a drop rate of 0.2", len(keys), n)
+	}
+
+	seen := make(map[string]bool)
+	reordered := 0
+	prev := -1
+	for _, k := range keys {
+		if seen[k] {
+			t.Fatalf("message %s delivered twice", k)
+		}
+		seen[k] = true
+		i, _ := strconv.Atoi(k)
+		if i < prev {
+			reordered++
+		}
+		prev = i
+	}
+	if reordered == 0 {
+		t.Error("no message has been reordered")
+	}
+}
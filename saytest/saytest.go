@@ -0,0 +1,89 @@
+// Package saytest provides helpers to test the messages printed with Say.
+package saytest
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/say.v0"
+)
+
+var update = flag.Bool("saytest.update", false, "update the golden files")
+
+// Golden runs f, captures the messages it sends and compares them to the
+// content of the golden file at path. If the test binary is run with the
+// -saytest.update flag, the golden file is written instead.
+//
+// Messages are written in the format of Message.WriteTo without the timestamp
+// and are normalized so that the golden file is deterministic: durations are
+// replaced by 0ms and stack traces are removed.
+//
+// Golden uses SetListener and restores the default behavior when it returns,
+// so it must not be used concurrently or while a listener is set.
+func Golden(t testing.TB, path string, f func()) {
+	got := Capture(f)
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("saytest: cannot create golden file directory: %v", err)
+		}
+		if err := ioutil.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("saytest: cannot write golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("saytest: cannot read golden file (run with -saytest.update "+
+			"to create it): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("saytest: output differs from %s at line %d\n"+
+			"got:\n%s\nwant:\n%s", path, diffLine(got, want), got, want)
+	}
+}
+
+// Capture runs f and returns the normalized messages it sends, as written in
+// golden files.
+func Capture(f func()) []byte {
+	buf := new(bytes.Buffer)
+	say.SetListener(func(m *say.Message) {
+		normalize(m)
+		if _, err := m.WriteWithoutTimestampTo(buf); err != nil {
+			panic(err)
+		}
+	})
+	defer say.SetListener(nil)
+
+	f()
+	say.Flush()
+	return buf.Bytes()
+}
+
+func normalize(m *say.Message) {
+	if m.Unit == "ms" {
+		m.Value = 0
+	}
+	m.DiscardStackTrace()
+}
+
+// diffLine returns the number of the first line that differs between a and b.
+func diffLine(a, b []byte) int {
+	la := strings.Split(string(a), "\n")
+	lb := strings.Split(string(b), "\n")
+	for i := 0; i < len(la) && i < len(lb); i++ {
+		if la[i] != lb[i] {
+			return i + 1
+		}
+	}
+	if len(la) < len(lb) {
+		return len(la)
+	}
+	return len(lb)
+}
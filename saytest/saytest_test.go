@@ -0,0 +1,70 @@
+package saytest
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"gopkg.in/say.v0"
+)
+
+func run() {
+	log := say.NewLogger()
+	log.SetData("id", 5)
+	log.Info("Starting...")
+	log.Time("duration", func() { time.Sleep(2 * time.Millisecond) })
+	log.Event("done", "ok", true)
+	log.Error(errors.New("oops"))
+}
+
+func TestGolden(t *testing.T) {
+	Golden(t, "testdata/run.golden", run)
+}
+
+type recorder struct {
+	testing.TB
+	failed bool
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failed = true
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.failed = true
+	panic(fmt.Sprintf(format, args...))
+}
+
+func TestGoldenMismatch(t *testing.T) {
+	r := &recorder{TB: t}
+	Golden(r, "testdata/run.golden", func() {
+		say.Info("Starting...")
+	})
+	if !r.failed {
+		t.Error("Golden did not fail with a different output")
+	}
+}
+
+func TestGoldenMissingFile(t *testing.T) {
+	r := &recorder{TB: t}
+	func() {
+		defer func() { recover() }()
+		Golden(r, "testdata/missing.golden", run)
+	}()
+	if !r.failed {
+		t.Error("Golden did not fail with a missing golden file")
+	}
+}
+
+func TestCaptureTimeFormat(t *testing.T) {
+	defer say.SetTimeFormat(say.DefaultTimeLayout, nil)
+
+	for _, layout := range []string{time.RFC3339, say.EpochMillis} {
+		say.SetTimeFormat(layout, time.UTC)
+		got := string(Capture(func() { say.Info("hello world") }))
+		if want := "INFO  hello world\n"; got != want {
+			t.Errorf("Capture() with the %q layout = %q, want %q", layout, got, want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package say
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// HandleSignals makes the program exit cleanly when it receives one of the
+// given signals: an EVENT shutdown is sent, the message queue is flushed so
+// that no message is lost and the program exits.
+//
+//	say.HandleSignals(os.Interrupt, syscall.SIGTERM)
+//
+// Without signals, os.Interrupt and syscall.SIGTERM are handled. Other signals,
+// such as SIGWINCH or the SIGURG used by the Go runtime, do not stop programs.
+//
+// The exit status is 128 plus the signal number, as with shells. On js/wasm,
+// where programs receive no signal, HandleSignals has no effect.
+func HandleSignals(sigs ...os.Signal) {
+	HandleSignalsFunc(nil, sigs...)
+}
+
+// HandleSignalsFunc is like HandleSignals but also runs f, if not nil, after
+// the message queue is flushed and before exiting. Use it to release
+// resources on shutdown.
+func HandleSignalsFunc(f func(os.Signal), sigs ...os.Signal) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	c := make(chan os.Signal, 1)
+	notify(c, sigs...)
+	go func() {
+		handleSignal(<-c, f)
+	}()
+}
+
+func handleSignal(sig os.Signal, f func(os.Signal)) {
+	Event("shutdown", "signal", sig.String())
+	Flush()
+	if f != nil {
+		f(sig)
+	}
+
+	code := 1
+	if s, ok := sig.(syscall.Signal); ok {
+		code = 128 + int(s)
+	}
+	exitWith(code)
+}
+
+// Stubbed out for testing.
+var notify = signal.Notify
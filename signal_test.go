@@ -0,0 +1,52 @@
+package say
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+)
+
+func TestHandleSignal(t *testing.T) {
+	var code int
+	exit = func(c int) {
+		code = c
+	}
+	defer func() {
+		exit = func(int) {}
+	}()
+
+	var got os.Signal
+	expect(t, func() {
+		handleSignal(syscall.SIGTERM, func(sig os.Signal) {
+			got = sig
+		})
+	}, []string{
+		`EVENT shutdown	| signal="` + syscall.SIGTERM.String() + `"`,
+	})
+	if got != syscall.SIGTERM {
+		t.Errorf("callback got %v, want %v", got, syscall.SIGTERM)
+	}
+	if want := 128 + int(syscall.SIGTERM); code != want {
+		t.Errorf("exit code = %d, want %d", code, want)
+	}
+}
+
+func TestHandleSignalsDefault(t *testing.T) {
+	var got []os.Signal
+	notify = func(c chan<- os.Signal, sigs ...os.Signal) {
+		got = sigs
+	}
+	defer func() {
+		notify = signal.Notify
+	}()
+
+	HandleSignals()
+	if len(got) != 2 || got[0] != os.Interrupt || got[1] != syscall.SIGTERM {
+		t.Errorf("HandleSignals() handles %v, want [%v %v]", got, os.Interrupt, syscall.SIGTERM)
+	}
+	HandleSignals(os.Kill)
+	if len(got) != 1 || got[0] != os.Kill {
+		t.Errorf("HandleSignals(os.Kill) handles %v, want [%v]", got, os.Kill)
+	}
+}
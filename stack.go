@@ -0,0 +1,273 @@
+package say
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var compactStackTraces bool
+
+// CompactStackTraces sets whether the stack traces of the ERROR and FATAL
+// messages are compacted. It is off by default.
+//
+// Compacted stack traces have the GOROOT prefix of file paths replaced by
+// $GOROOT and the repeated sequences of frames (e.g. from recursive calls)
+// collapsed. Frames are compared regardless of the arguments of the calls. Use
+// ExpandStackTrace to restore them: the arguments of the collapsed frames are
+// then the ones of the first sequence.
+func CompactStackTraces(b bool) {
+	mu.Lock()
+	compactStackTraces = b
+	mu.Unlock()
+}
+
+var deferStackTraces bool
+
+// DeferStackTraces sets whether the symbolization of stack traces is deferred.
+// It is off by default.
+//
+// When on, Error and Fatal only capture the program counters of the stack,
+// which is fast, and the stack trace is built the first time it is needed:
+// when Message.StackTrace is called or when the message is printed. Listeners
+// that do not need the stack traces do not pay for them. Function arguments
+// are not printed in these stack traces.
+func DeferStackTraces(b bool) {
+	mu.Lock()
+	deferStackTraces = b
+	mu.Unlock()
+}
+
+const maxStackDepth = 64
+
+// callers returns the program counters of the stack of the calling goroutine,
+// skipping the frame of callers and skip more frames.
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, maxStackDepth)
+	return pcs[:runtime.Callers(skip+2, pcs)]
+}
+
+// symbolize returns the stack trace of the program counters pcs.
+func symbolize(pcs []uintptr) string {
+	st := getBuffer()
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		if len(st.buf) > 0 {
+			st.appendByte('\n')
+		}
+		st.appendString(f.Function)
+		st.appendString("(...)\n\t")
+		st.appendString(f.File)
+		st.appendByte(':')
+		st.appendInt(int64(f.Line))
+		if f.Entry != 0 {
+			st.appendString(" +0x")
+			st.buf = strconv.AppendUint(st.buf, uint64(f.PC-f.Entry), 16)
+		}
+		if !more {
+			break
+		}
+	}
+
+	mu.RLock()
+	compact := compactStackTraces
+	mu.RUnlock()
+
+	if compact {
+		return compactStackTrace(st.String())
+	}
+	return st.String()
+}
+
+const (
+	gorootVar    = "$GOROOT"
+	repeatPrefix = "\t... previous "
+	maxPeriod    = 16 // Longest sequence of frames that can be collapsed.
+)
+
+var goroot = runtime.GOROOT()
+
+// compactStackTrace compacts the stack trace st as described in
+// CompactStackTraces.
+func compactStackTrace(st string) string {
+	if goroot != "" {
+		st = strings.Replace(st, "\t"+goroot+"/", "\t"+gorootVar+"/", -1)
+	}
+
+	frames := splitFrames(st)
+	buf := getBuffer()
+	for i := 0; i < len(frames); {
+		period, reps := longestRepetition(frames, i)
+		for _, f := range frames[i : i+period] {
+			appendFrame(buf, f)
+		}
+		i += period
+		if reps > 0 {
+			appendFrame(buf, repeatPrefix+strconv.Itoa(period)+" frames repeated "+
+				strconv.Itoa(reps)+" more times")
+			i += period * reps
+		}
+	}
+	return buf.String()
+}
+
+// ExpandStackTrace restores a stack trace compacted as described in
+// CompactStackTraces. Other stack traces are returned unchanged.
+func ExpandStackTrace(st string) string {
+	if goroot != "" {
+		st = strings.Replace(st, "\t"+gorootVar+"/", "\t"+goroot+"/", -1)
+	}
+	if !strings.Contains(st, repeatPrefix) {
+		return st
+	}
+
+	frames := splitFrames(st)
+	expanded := make([]string, 0, len(frames))
+	for _, f := range frames {
+		period, reps, ok := parseRepetition(f)
+		if !ok || period > len(expanded) {
+			expanded = append(expanded, f)
+			continue
+		}
+		seq := expanded[len(expanded)-period:]
+		for j := 0; j < reps; j++ {
+			expanded = append(expanded, seq...)
+		}
+	}
+
+	buf := getBuffer()
+	for _, f := range expanded {
+		appendFrame(buf, f)
+	}
+	return buf.String()
+}
+
+// splitFrames splits a stack trace into frames. A frame is made of the
+// function line and the file line that follows it, if any.
+func splitFrames(st string) []string {
+	lines := strings.Split(st, "\n")
+	frames := make([]string, 0, len(lines)/2+1)
+	for i := 0; i < len(lines); i++ {
+		if i+1 < len(lines) && strings.HasPrefix(lines[i+1], "\t") &&
+			!strings.HasPrefix(lines[i+1], repeatPrefix) {
+			frames = append(frames, lines[i]+"\n"+lines[i+1])
+			i++
+			continue
+		}
+		frames = append(frames, lines[i])
+	}
+	return frames
+}
+
+func appendFrame(buf *buffer, f string) {
+	if len(buf.buf) > 0 {
+		buf.appendByte('\n')
+	}
+	buf.appendString(f)
+}
+
+// longestRepetition returns the length of the sequence of frames starting at i
+// whose consecutive repetitions cover the most frames, and the number of times
+// it is repeated. If no sequence is repeated, it returns (1, 0).
+func longestRepetition(frames []string, i int) (period, reps int) {
+	period = 1
+	best := 0
+	for p := 1; p <= maxPeriod && i+2*p <= len(frames); p++ {
+		r := 0
+		for start := i + p; start+p <= len(frames) &&
+			equalFrames(frames[i:i+p], frames[start:start+p]); start += p {
+			r++
+		}
+		if r > 0 && p*r > best {
+			period, reps, best = p, r, p*r
+		}
+	}
+	return period, reps
+}
+
+func equalFrames(a, b []string) bool {
+	for i := range a {
+		if frameKey(a[i]) != frameKey(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// frameKey returns the frame f without the arguments of the function call.
+func frameKey(f string) string {
+	i := strings.IndexByte(f, '\n')
+	if i == -1 {
+		return f
+	}
+	if j := strings.LastIndexByte(f[:i], '('); j != -1 {
+		return f[:j] + f[i:]
+	}
+	return f
+}
+
+// parseRepetition parses a line added by compactStackTrace.
+func parseRepetition(line string) (period, reps int, ok bool) {
+	if !strings.HasPrefix(line, repeatPrefix) {
+		return 0, 0, false
+	}
+	fields := strings.Fields(line[len(repeatPrefix):])
+	if len(fields) != 6 {
+		return 0, 0, false
+	}
+	period, err1 := strconv.Atoi(fields[0])
+	reps, err2 := strconv.Atoi(fields[3])
+	return period, reps, err1 == nil && err2 == nil && period > 0
+}
+
+// maxTraceCounts is the maximum number of errors whose stack traces are
+// counted per interval by the stack trace budget.
+const maxTraceCounts = 1000
+
+var (
+	traceBudget         int
+	traceBudgetInterval time.Duration
+	traceBudgetStart    time.Time
+	traceCounts         map[string]int
+)
+
+// SetStackTraceBudget limits the number of stack traces captured for a given
+// error to max per interval, since capturing stack traces is expensive. Errors
+// over the budget are printed without their stack trace and with a
+// trace_suppressed=true data pair.
+//
+// Errors are told apart by their type (ERROR or FATAL) and message. To bound
+// the memory used, at most 1000 distinct errors are counted per interval; the
+// stack traces of the other errors are suppressed until the end of the
+// interval. A max of 0 removes the budget, which is the default.
+func SetStackTraceBudget(max int, interval time.Duration) {
+	mu.Lock()
+	traceBudget = max
+	traceBudgetInterval = interval
+	traceCounts = nil
+	mu.Unlock()
+}
+
+// withinTraceBudget reports whether the stack trace of the given error can be
+// captured and counts it. It must be called with mu locked.
+func withinTraceBudget(typ Type, msg []byte) bool {
+	if traceBudget <= 0 {
+		return true
+	}
+
+	t := now()
+	if traceCounts == nil || t.Sub(traceBudgetStart) >= traceBudgetInterval {
+		traceCounts = make(map[string]int)
+		traceBudgetStart = t
+	}
+
+	key := string(typ) + string(msg)
+	n, ok := traceCounts[key]
+	if n >= traceBudget || !ok && len(traceCounts) >= maxTraceCounts {
+		return false
+	}
+	traceCounts[key]++
+	return true
+}
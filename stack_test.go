@@ -0,0 +1,168 @@
+package say
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/say.v0/sayclock"
+)
+
+func TestCompactStackTrace(t *testing.T) {
+	frame := func(name string) string {
+		return name + "()\n\t/home/me/go/src/main.go:12 +0x1d"
+	}
+	var frames []string
+	frames = append(frames, frame("main.a"))
+	for i := 0; i < 5; i++ {
+		frames = append(frames, frame("main.b"), frame("main.c"))
+	}
+	frames = append(frames, frame("main.main"),
+		"runtime.main()\n\t"+goroot+"/src/runtime/proc.go:283 +0x28b")
+	st := strings.Join(frames, "\n")
+
+	want := strings.Join([]string{
+		frame("main.a"),
+		frame("main.b"),
+		frame("main.c"),
+		"\t... previous 2 frames repeated 4 more times",
+		frame("main.main"),
+		"runtime.main()\n\t$GOROOT/src/runtime/proc.go:283 +0x28b",
+	}, "\n")
+	if goroot == "" {
+		want = strings.Replace(want, "$GOROOT", "", 1)
+	}
+
+	got := compactStackTrace(st)
+	if got != want {
+		t.Errorf("compactStackTrace() =\n%s\nwant:\n%s", got, want)
+	}
+	if exp := ExpandStackTrace(got); exp != st {
+		t.Errorf("ExpandStackTrace() =\n%s\nwant:\n%s", exp, st)
+	}
+}
+
+func TestExpandStackTraceUnchanged(t *testing.T) {
+	st := "main.main()\n\t/home/me/go/src/main.go:12 +0x1d"
+	if got := ExpandStackTrace(st); got != st {
+		t.Errorf("ExpandStackTrace(%q) = %q, want unchanged", st, got)
+	}
+}
+
+func recurse(n int, f func()) {
+	if n == 0 {
+		f()
+		return
+	}
+	recurse(n-1, f)
+}
+
+func TestCompactStackTracesOption(t *testing.T) {
+	CompactStackTraces(true)
+	defer CompactStackTraces(false)
+
+	var st string
+	SetListener(func(m *Message) {
+		st = m.StackTrace()
+	})
+	log := NewLogger(SkipStackFrames(0))
+	recurse(10, func() { log.Error("foo") })
+	Flush()
+	SetListener(nil)
+
+	if strings.Count(st, "say.v0.recurse") > 2 || !strings.Contains(st, repeatPrefix) {
+		t.Errorf("recursive frames are not collapsed:\n%s", st)
+	}
+}
+
+func TestStackTraceBudget(t *testing.T) {
+	c := sayclock.NewFake(time.Date(2015, 9, 1, 21, 37, 0, 0, time.UTC))
+	SetClock(c)
+	defer SetClock(sayclock.Real)
+	SetStackTraceBudget(2, time.Minute)
+	defer SetStackTraceBudget(0, 0)
+
+	var traces []bool
+	var suppressed []bool
+	SetListener(func(m *Message) {
+		traces = append(traces, m.StackTrace() != "")
+		_, ok := m.Data.Get("trace_suppressed")
+		suppressed = append(suppressed, ok)
+	})
+	log := NewLogger(SkipStackFrames(0))
+	for i := 0; i < 3; i++ {
+		log.Error("foo")
+	}
+	log.Error("bar")
+	log.Fatal("foo")
+	c.Add(time.Minute)
+	log.Error("foo")
+	Flush()
+	SetListener(nil)
+
+	wantTraces := []bool{true, true, false, true, true, true}
+	for i := range wantTraces {
+		if traces[i] != wantTraces[i] || suppressed[i] == wantTraces[i] {
+			t.Errorf("message %d: has stack trace = %t, trace_suppressed = "+
+				"%t, want %t, %t", i, traces[i], suppressed[i],
+				wantTraces[i], !wantTraces[i])
+		}
+	}
+}
+
+func TestStackTraceBudgetSize(t *testing.T) {
+	c := sayclock.NewFake(time.Date(2015, 9, 1, 21, 37, 0, 0, time.UTC))
+	SetClock(c)
+	defer SetClock(sayclock.Real)
+	SetStackTraceBudget(1, time.Minute)
+	defer SetStackTraceBudget(0, 0)
+
+	mu.Lock()
+	defer mu.Unlock()
+	for i := 0; i < maxTraceCounts; i++ {
+		if !withinTraceBudget(TypeError, []byte(strconv.Itoa(i))) {
+			t.Fatalf("error %d is over the budget", i)
+		}
+	}
+	if withinTraceBudget(TypeError, []byte("foo")) {
+		t.Error("a new error is within the budget once the map is full")
+	}
+	if len(traceCounts) != maxTraceCounts {
+		t.Errorf("%d errors counted, want %d", len(traceCounts), maxTraceCounts)
+	}
+	c.Add(time.Minute)
+	if !withinTraceBudget(TypeError, []byte("foo")) {
+		t.Error("a new error is over the budget in a new interval")
+	}
+}
+
+func TestDeferStackTraces(t *testing.T) {
+	DeferStackTraces(true)
+	defer DeferStackTraces(false)
+
+	var st string
+	SetListener(func(m *Message) {
+		st = m.StackTrace()
+	})
+	NewLogger(SkipStackFrames(0)).Error("foo")
+	Flush()
+	SetListener(nil)
+
+	if !strings.Contains(st, "TestDeferStackTraces(...)\n\t") ||
+		!strings.Contains(st, "/stack_test.go:") {
+		t.Errorf("stack trace does not start at the caller:\n%s", st)
+	}
+	if strings.Contains(st, "(*Logger).error") || strings.Contains(st, "callers") {
+		t.Errorf("stack trace contains frames of the say package:\n%s", st)
+	}
+
+	buf := new(bytes.Buffer)
+	w := Redirect(buf)
+	NewLogger(SkipStackFrames(0)).Error("foo")
+	Redirect(w)
+	if !strings.Contains(buf.String(), "TestDeferStackTraces(...)") {
+		t.Errorf("printed stack trace is missing:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,134 @@
+package say
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var errSummaryTop = errors.New("say: the error summary top must not be negative")
+
+var (
+	summaryMu       sync.Mutex
+	summaryInterval time.Duration
+	summaryTop      int
+	summaryCounts   map[string]int
+	summaryStop     func() // Stops the periodic summary.
+	summaryOn       int32  // Accessed atomically.
+)
+
+// SetErrorSummary makes Say count the ERROR, FATAL and WARN messages per
+// message and print a SUMRY message listing the most frequent ones every
+// interval:
+//
+//	SUMRY error summary	| errors=12 warnings=3 top_1="ERROR connection refused (10)" top_2="WARN  slow query (3)"
+//
+// The summary lists the top most frequent messages of the last interval. The
+// intervals are measured by the clock set with SetClock, in a goroutine. No
+// summary is printed for an interval without errors. Flush also prints the
+// pending summary.
+//
+// A top of 0 only prints the numbers of errors and warnings. SetErrorSummary
+// panics if top is negative.
+//
+// An interval of 0 disables the summary, which is the default.
+//
+// This function must not be called concurrently with itself.
+func SetErrorSummary(interval time.Duration, top int) {
+	if top < 0 {
+		panic(errSummaryTop)
+	}
+
+	summaryMu.Lock()
+	stop := summaryStop
+	summaryStop = nil
+	summaryMu.Unlock()
+	if stop != nil {
+		stop()
+	}
+
+	summaryMu.Lock()
+	summaryInterval = interval
+	summaryTop = top
+	summaryCounts = make(map[string]int)
+	on := int32(0)
+	if interval > 0 {
+		on = 1
+		summaryStop = every(interval, flushSummary)
+	}
+	atomic.StoreInt32(&summaryOn, on)
+	summaryMu.Unlock()
+}
+
+// addToSummary counts msg in the error summary.
+func addToSummary(msg *Message) {
+	if atomic.LoadInt32(&summaryOn) == 0 {
+		return
+	}
+
+	summaryMu.Lock()
+	switch msg.Type {
+	case TypeError, TypeFatal:
+		summaryCounts[string(TypeError)+" "+msg.Error()]++
+	case TypeWarning:
+		summaryCounts[string(TypeWarning)+" "+msg.Content]++
+	}
+	summaryMu.Unlock()
+}
+
+// flushSummary prints the summary of the errors counted so far, if any, and
+// starts a new interval.
+func flushSummary() {
+	if atomic.LoadInt32(&summaryOn) == 0 {
+		return
+	}
+
+	summaryMu.Lock()
+	data := summaryData()
+	summaryCounts = make(map[string]int)
+	summaryMu.Unlock()
+
+	if data != nil {
+		defaultLogger.send(TypeSummary, "error summary", data)
+	}
+}
+
+// summaryData returns the data of the summary message, or nil if no error
+// has been counted. It must be called with summaryMu locked.
+func summaryData() []interface{} {
+	if len(summaryCounts) == 0 {
+		return nil
+	}
+
+	var errs, warnings int
+	keys := make([]string, 0, len(summaryCounts))
+	for k, n := range summaryCounts {
+		if strings.HasPrefix(k, string(TypeWarning)) {
+			warnings += n
+		} else {
+			errs += n
+		}
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ni, nj := summaryCounts[keys[i]], summaryCounts[keys[j]]
+		if ni != nj {
+			return ni > nj
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > summaryTop {
+		keys = keys[:summaryTop]
+	}
+
+	data := []interface{}{"errors", errs, "warnings", warnings}
+	for i, k := range keys {
+		data = append(data, "top_"+strconv.Itoa(i+1),
+			k+" ("+strconv.Itoa(summaryCounts[k])+")")
+	}
+	return data
+}
@@ -0,0 +1,93 @@
+package say
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gopkg.in/say.v0/sayclock"
+)
+
+func TestErrorSummary(t *testing.T) {
+	SetErrorSummary(time.Hour, 2)
+	defer SetErrorSummary(0, 0)
+
+	expect(t, func() {
+		for i := 0; i < 3; i++ {
+			Error(errors.New("connection refused"))
+		}
+		Warning("slow query")
+		Warning("slow query")
+		Fatal("oops")
+		Flush()
+		Event("foo")
+		Flush()
+	}, []string{
+		`ERROR connection refused`,
+		`ERROR connection refused`,
+		`ERROR connection refused`,
+		`WARN  slow query`,
+		`WARN  slow query`,
+		`FATAL oops`,
+		`SUMRY error summary	| errors=4 warnings=2 ` +
+			`top_1="ERROR connection refused (3)" top_2="WARN  slow query (2)"`,
+		`EVENT foo`,
+	})
+}
+
+func TestErrorSummaryInterval(t *testing.T) {
+	c := sayclock.NewFake(time.Date(2015, 9, 1, 21, 37, 0, 0, time.UTC))
+	SetClock(c)
+	defer SetClock(sayclock.Real)
+
+	msgs := make(chan string, 10)
+	SetListener(func(m *Message) {
+		buf := getBuffer()
+		buf.appendMessage(m)
+		msgs <- buf.String()
+	})
+	defer SetListener(nil)
+	SetErrorSummary(time.Minute, 1)
+	defer SetErrorSummary(0, 0)
+
+	receive := func(want string) {
+		t.Helper()
+		select {
+		case got := <-msgs:
+			if got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no message received, want %q", want)
+		}
+	}
+
+	// The summary is printed at the end of the interval even if no error
+	// follows.
+	Warning("slow query")
+	receive("WARN  slow query\n")
+	c.Add(time.Minute)
+	receive("SUMRY error summary\t| errors=0 warnings=1 top_1=\"WARN  slow query (1)\"\n")
+}
+
+func TestErrorSummaryTop(t *testing.T) {
+	SetErrorSummary(time.Hour, 0)
+	defer SetErrorSummary(0, 0)
+
+	expect(t, func() {
+		Error("oops")
+		Warning("slow query")
+		Flush()
+	}, []string{
+		`ERROR oops`,
+		`WARN  slow query`,
+		`SUMRY error summary	| errors=1 warnings=1`,
+	})
+
+	defer func() {
+		if err := recover(); err != errSummaryTop {
+			t.Errorf("SetErrorSummary(time.Hour, -1) = %v, want %v", err, errSummaryTop)
+		}
+	}()
+	SetErrorSummary(time.Hour, -1)
+}
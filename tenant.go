@@ -0,0 +1,127 @@
+package say
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// ForTenant returns a new Logger whose messages are sent on behalf of the given
+// tenant. The messages are tagged with a tenant data pair and their Tenant
+// field is set so that listeners can partition them per tenant.
+//
+// The messages of each tenant are limited by the quota set with
+// SetTenantQuota.
+func (l *Logger) ForTenant(id string) *Logger {
+	log := l.NewLogger()
+	log.tenant = id
+	log.AddData("tenant", id)
+	return log
+}
+
+// ForTenant returns a new Logger whose messages are sent on behalf of the given
+// tenant. The messages are tagged with a tenant data pair and their Tenant
+// field is set so that listeners can partition them per tenant.
+//
+// The messages of each tenant are limited by the quota set with
+// SetTenantQuota.
+func ForTenant(id string) *Logger {
+	return defaultLogger.ForTenant(id)
+}
+
+type tenantQuota struct {
+	count   int
+	dropped int
+	logger  *Logger // The Logger of the last dropped message.
+}
+
+var (
+	quotaMu       sync.Mutex
+	quotaMax      int
+	quotaInterval time.Duration
+	quotas        = make(map[string]*tenantQuota)
+	quotaStop     func() // Stops the periodic windows.
+)
+
+// SetTenantQuota limits the number of messages sent on behalf of each tenant to
+// max per interval. The messages over the quota are dropped and, when the
+// interval ends, a WARN message tells how many were. The intervals are
+// measured by the clock set with SetClock, in a goroutine.
+//
+// A max of 0 removes the quota, which is the default.
+//
+// This function must not be called concurrently with itself.
+func SetTenantQuota(max int, interval time.Duration) {
+	quotaMu.Lock()
+	stop := quotaStop
+	quotaStop = nil
+	quotaMu.Unlock()
+	if stop != nil {
+		stop()
+		closeQuotaWindow()
+	}
+
+	quotaMu.Lock()
+	quotaMax = max
+	quotaInterval = interval
+	quotas = make(map[string]*tenantQuota)
+	if max > 0 && interval > 0 {
+		quotaStop = every(interval, closeQuotaWindow)
+	} else {
+		quotaMax = 0
+	}
+	quotaMu.Unlock()
+}
+
+// withinTenantQuota reports whether a message of the Logger's tenant can be
+// sent without exceeding its quota.
+func (l *Logger) withinTenantQuota() bool {
+	if l.tenant == "" {
+		return true
+	}
+
+	quotaMu.Lock()
+	if quotaMax <= 0 {
+		quotaMu.Unlock()
+		return true
+	}
+
+	q, ok := quotas[l.tenant]
+	if !ok {
+		q = new(tenantQuota)
+		quotas[l.tenant] = q
+	}
+
+	within := q.count < quotaMax
+	if within {
+		q.count++
+	} else {
+		q.dropped++
+		q.logger = l
+	}
+	quotaMu.Unlock()
+	return within
+}
+
+// closeQuotaWindow prints how many messages each tenant got dropped, sorted by
+// tenant, and starts a new interval.
+func closeQuotaWindow() {
+	quotaMu.Lock()
+	tenants := make([]string, 0, len(quotas))
+	for id, q := range quotas {
+		if q.dropped > 0 {
+			tenants = append(tenants, id)
+		}
+	}
+	sort.Strings(tenants)
+	dropped := make([]tenantQuota, len(tenants))
+	for i, id := range tenants {
+		dropped[i] = *quotas[id]
+	}
+	quotas = make(map[string]*tenantQuota)
+	quotaMu.Unlock()
+
+	for _, q := range dropped {
+		q.logger.Warning("tenant quota exceeded", "dropped", q.dropped)
+	}
+}
@@ -0,0 +1,92 @@
+package say
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/say.v0/sayclock"
+)
+
+func TestForTenant(t *testing.T) {
+	var tenants []string
+	SetListener(func(m *Message) {
+		tenants = append(tenants, m.Tenant)
+	})
+	log := ForTenant("acme")
+	log.Info("foo")
+	log.NewLogger().Info("foo")
+	Info("foo")
+	Flush()
+	SetListener(nil)
+
+	want := []string{"acme", "acme", ""}
+	if len(tenants) != len(want) {
+		t.Fatalf("tenants = %q, want %q", tenants, want)
+	}
+	for i := range want {
+		if tenants[i] != want[i] {
+			t.Errorf("tenants = %q, want %q", tenants, want)
+		}
+	}
+
+	expect(t, func() {
+		log.Info("foo")
+	}, []string{
+		`INFO  foo	| tenant="acme"`,
+	})
+}
+
+func TestTenantQuota(t *testing.T) {
+	c := sayclock.NewFake(time.Date(2015, 9, 1, 21, 37, 0, 0, time.UTC))
+	SetClock(c)
+	defer SetClock(sayclock.Real)
+
+	msgs := make(chan string, 20)
+	SetListener(func(m *Message) {
+		buf := getBuffer()
+		buf.appendMessage(m)
+		msgs <- buf.String()
+	})
+	defer SetListener(nil)
+	SetTenantQuota(2, time.Minute)
+	defer SetTenantQuota(0, 0)
+
+	receive := func(want ...string) {
+		t.Helper()
+		for _, w := range want {
+			select {
+			case got := <-msgs:
+				if got != w+"\n" {
+					t.Errorf("got %q, want %q", got, w+"\n")
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("no message received, want %q", w)
+			}
+		}
+	}
+
+	acme := ForTenant("acme")
+	initech := ForTenant("initech")
+	for i := 0; i < 4; i++ {
+		acme.Event("foo")
+	}
+	initech.Event("bar")
+	Event("baz")
+	Event("baz")
+	Event("baz")
+	receive(
+		`EVENT foo	| tenant="acme"`,
+		`EVENT foo	| tenant="acme"`,
+		`EVENT bar	| tenant="initech"`,
+		`EVENT baz`,
+		`EVENT baz`,
+		`EVENT baz`,
+	)
+
+	// The tenant stops sending messages: the drops are reported when the
+	// interval ends.
+	c.Add(time.Minute)
+	receive(`WARN  tenant quota exceeded	| tenant="acme" dropped=2`)
+	acme.Event("foo")
+	receive(`EVENT foo	| tenant="acme"`)
+}
@@ -0,0 +1,88 @@
+package say
+
+import "time"
+
+// DefaultTimeLayout is the layout of the timestamps written by Message.WriteTo
+// by default.
+const DefaultTimeLayout = "2006-01-02 15:04:05.000"
+
+// EpochMillis is a layout for SetTimeFormat that writes timestamps as the
+// number of milliseconds elapsed since the Unix epoch.
+const EpochMillis = "epoch_ms"
+
+var (
+	timeLayout   = DefaultTimeLayout
+	timeLocation *time.Location
+	printLayout  string
+)
+
+// SetTimeFormat sets the layout and the location of the timestamps written by
+// Message.WriteTo. The layout is either a time.Time layout, such as
+// time.RFC3339, or EpochMillis.
+//
+// The location also applies to the timestamps written by Message.WriteJSONTo,
+// which are always RFC 3339, by TimeHook and to the ones printed with
+// WithTimestamps. Use time.UTC to write UTC timestamps whatever the local
+// time. A nil location keeps the location of the clock, which is the local time
+// with sayclock.Real.
+//
+// This function must not be called concurrently with the other functions of
+// this package.
+func SetTimeFormat(layout string, loc *time.Location) {
+	timeLayout = layout
+	timeLocation = loc
+}
+
+// WithTimestamps makes the messages printed to the output start with a
+// timestamp, like the ones written by Message.WriteTo. The layout is either a
+// time.Time layout or EpochMillis and the location is the one set with
+// SetTimeFormat. WithTimestamps("") removes the timestamps, which is the
+// default.
+//
+// It is only effective when SetListener has not been used.
+func WithTimestamps(layout string) {
+	mu.Lock()
+	printLayout = layout
+	mu.Unlock()
+}
+
+// appendTimestamp appends t formatted with the layout and the location set with
+// SetTimeFormat.
+func (b *buffer) appendTimestamp(t time.Time) {
+	b.appendTimestampLayout(t, timeLayout)
+}
+
+// appendTimestampLayout appends t formatted with layout and the location set
+// with SetTimeFormat.
+func (b *buffer) appendTimestampLayout(t time.Time, layout string) {
+	t = inTimeLocation(t)
+
+	switch layout {
+	case DefaultTimeLayout:
+		b.appendDigits(t.Year(), 4)
+		b.appendByte('-')
+		b.appendDigits(int(t.Month()), 2)
+		b.appendByte('-')
+		b.appendDigits(t.Day(), 2)
+		b.appendByte(' ')
+		b.appendDigits(t.Hour(), 2)
+		b.appendByte(':')
+		b.appendDigits(t.Minute(), 2)
+		b.appendByte(':')
+		b.appendDigits(t.Second(), 2)
+		b.appendByte('.')
+		b.appendDigits(t.Nanosecond()/int(time.Millisecond), 3)
+	case EpochMillis:
+		b.appendInt(t.UnixNano() / int64(time.Millisecond))
+	default:
+		b.buf = t.AppendFormat(b.buf, layout)
+	}
+}
+
+// inTimeLocation returns t in the location set with SetTimeFormat.
+func inTimeLocation(t time.Time) time.Time {
+	if timeLocation != nil {
+		return t.In(timeLocation)
+	}
+	return t
+}